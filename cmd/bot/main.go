@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"mot-bot/pkg/cache"
 	"mot-bot/pkg/db"
+	"mot-bot/pkg/env"
 	"mot-bot/pkg/mot"
 	"mot-bot/pkg/telegram"
 	"mot-bot/pkg/ves"
@@ -11,6 +14,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/joho/godotenv"
@@ -43,10 +47,8 @@ func main() {
 		log.Fatal("MOT_CLIENT_SECRET environment variable is not set")
 	}
 
-	const motBaseURL = "https://history.mot.api.gov.uk/v1/trade/vehicles"
-
 	motTokenURL := os.Getenv("MOT_TOKEN_URL")
-	if motClientSecret == "" {
+	if motTokenURL == "" {
 		log.Fatal("MOT_TOKEN_URL environment variable is not set")
 	}
 
@@ -60,6 +62,17 @@ func main() {
 		log.Fatal("VES_API_BASE_URL environment variable is not set")
 	}
 
+	// Get webhook settings, long polling is used if no webhook URL is set
+	webhookURL := os.Getenv("TELEGRAM_WEBHOOK_URL")
+	webhookListen := os.Getenv("TELEGRAM_WEBHOOK_LISTEN")
+	if webhookListen == "" {
+		webhookListen = ":8080"
+	}
+	webhookSecret := os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+	if webhookURL != "" && webhookSecret == "" {
+		log.Fatal("TELEGRAM_WEBHOOK_SECRET environment variable must be set when TELEGRAM_WEBHOOK_URL is set")
+	}
+
 	// Get SQLite database path
 	dbPath := os.Getenv("SQLITE_DB_PATH")
 	if dbPath == "" {
@@ -72,6 +85,31 @@ func main() {
 		log.Println("Warning: BOT_ADMINS not set, /stats command will not be available to anyone")
 	}
 
+	// Get how many days before a due date reminders are sent
+	reminderDays := env.Int("REMINDER_DAYS_BEFORE", 14)
+
+	// Get how many updates are handled at once and how long each may take
+	workers := env.Int("BOT_WORKERS", 8)
+	requestTimeout := env.Duration("REQUEST_TIMEOUT", 30*time.Second)
+	bulkTimeout := env.Duration("BULK_TIMEOUT", 10*time.Minute)
+	if workers <= 0 {
+		log.Fatal("BOT_WORKERS must be at least 1")
+	}
+	if requestTimeout <= 0 {
+		log.Fatal("REQUEST_TIMEOUT must be a positive duration")
+	}
+	if bulkTimeout <= 0 {
+		log.Fatal("BULK_TIMEOUT must be a positive duration")
+	}
+
+	// Get how long API responses are cached, 0 disables the cache
+	cacheTTL := env.Duration("CACHE_TTL", time.Hour)
+
+	// Get retry and rate limits for the DVSA and DVLA APIs. The defaults match
+	// the DVSA trade API quota of 15 requests per second with a burst of 10.
+	motPolicy := env.Policy("MOT", 15, 10)
+	vesPolicy := env.Policy("VES", 10, 10)
+
 	// Ensure data directory exists
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		log.Fatalf("Failed to create data directory: %v", err)
@@ -85,16 +123,29 @@ func main() {
 	defer logger.Close()
 
 	// Create clients
-	motHTTPClient := mot.CreateHTTPClient(motClientID, motClientSecret, motTokenURL)
-	motClient := mot.NewClient(motHTTPClient, motAPIKey, motBaseURL)
-	vesClient := ves.NewClient(vesBaseURL, vesAPIKey)
+	motHTTPClient := mot.CreateHTTPClient(motClientID, motClientSecret, motTokenURL, motPolicy)
+	var motClient mot.ClientInterface = mot.NewClient(motHTTPClient, motAPIKey, mot.BaseURL)
+	var vesClient ves.ClientInterface = ves.NewClient(vesBaseURL, vesAPIKey, vesPolicy)
+
+	// Cache API responses so repeat lookups don't count against the quotas
+	if cacheTTL > 0 {
+		motClient = cache.NewMOTClient(motClient, logger, cacheTTL)
+		vesClient = cache.NewVESClient(vesClient, logger, cacheTTL)
+	}
 
 	// Create bot
 	tgBot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		log.Fatalf("Failed to create Telegram bot: %v", err)
 	}
-	bot := telegram.NewBot(tgBot, motClient, vesClient, logger, adminList)
+	bot := telegram.NewBot(tgBot, motClient, vesClient, logger, telegram.Config{
+		AdminList:      adminList,
+		ReminderDays:   reminderDays,
+		Workers:        workers,
+		RequestTimeout: requestTimeout,
+		BulkTimeout:    bulkTimeout,
+		CacheTTL:       cacheTTL,
+	})
 
 	// Create context that will be cancelled on SIGINT or SIGTERM
 	ctx, cancel := context.WithCancel(context.Background())
@@ -108,9 +159,20 @@ func main() {
 		cancel()
 	}()
 
-	// Start bot
-	log.Println("Starting bot...")
-	if err := bot.Start(ctx); err != nil {
+	// Start bot, using a webhook if one is configured and long polling otherwise
+	if webhookURL != "" {
+		log.Println("Starting bot in webhook mode...")
+		err = bot.StartWebhook(ctx, telegram.WebhookConfig{
+			URL:    webhookURL,
+			Listen: webhookListen,
+			Secret: webhookSecret,
+		})
+	} else {
+		log.Println("Starting bot...")
+		err = bot.Start(ctx)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
 		log.Printf("Bot stopped with error: %v", err)
 	}
+	log.Println("Bot stopped")
 }
@@ -0,0 +1,149 @@
+// Command motcli looks up the MOT history and DVLA details of vehicles from
+// the terminal, using the same API settings as the bot but no Telegram token.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"mot-bot/pkg/env"
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/plate"
+	"mot-bot/pkg/ves"
+
+	"github.com/joho/godotenv"
+)
+
+// Exit codes
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+// entry is a registration given on the command line or standard input
+type entry struct {
+	input        string
+	registration string
+	err          error
+	result       *lookup.Result
+}
+
+func main() {
+	format := flag.String("format", formatText, "output format: text, json, csv or csv-tests")
+	concurrency := flag.Int("concurrency", 5, "how many vehicles to look up at once")
+	timeout := flag.Duration("timeout", 5*time.Minute, "how long all lookups may take")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [reg...]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Looks up the MOT and tax status of vehicles. Without registrations")
+		fmt.Fprintln(flag.CommandLine.Output(), "as arguments, they are read from standard input, one per line.")
+		fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if !validFormat(*format) || *concurrency < 1 {
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+
+	// Try to load .env file, the environment variables are enough on their own
+	_ = godotenv.Load()
+
+	inputs := flag.Args()
+	if len(inputs) == 0 {
+		var err error
+		if inputs, err = readLines(os.Stdin); err != nil {
+			log.Fatalf("Failed to read registrations: %v", err)
+		}
+	}
+	entries := parseEntries(inputs)
+	if len(entries) == 0 {
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+
+	// Create clients
+	motHTTPClient := mot.CreateHTTPClient(env.Required("MOT_CLIENT_ID"), env.Required("MOT_CLIENT_SECRET"),
+		env.Required("MOT_TOKEN_URL"), env.Policy("MOT", 15, 10))
+	motClient := mot.NewClient(motHTTPClient, env.Required("MOT_API_KEY"), mot.BaseURL)
+	vesClient := ves.NewClient(env.Required("VES_API_BASE_URL"), env.Required("VES_API_KEY"), env.Policy("VES", 10, 10))
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	lookupAll(ctx, motClient, vesClient, entries, *concurrency)
+
+	if err := write(os.Stdout, *format, entries, time.Now()); err != nil {
+		log.Fatalf("Failed to write output: %v", err)
+	}
+
+	for _, e := range entries {
+		if e.failed() {
+			os.Exit(exitFailed)
+		}
+	}
+	os.Exit(exitOK)
+}
+
+// failed reports whether the registration was invalid or either API failed.
+// A vehicle too new to have an MOT record is not a failure.
+func (e *entry) failed() bool {
+	if e.err != nil {
+		return true
+	}
+	return e.result.VESErr != nil || (e.result.MOTErr != nil && !e.result.NoMOTRecord())
+}
+
+// readLines returns the non-empty lines of r
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// parseEntries validates the registrations
+func parseEntries(inputs []string) []*entry {
+	entries := make([]*entry, 0, len(inputs))
+	for _, input := range inputs {
+		registration, _, err := plate.Parse(input)
+		entries = append(entries, &entry{input: input, registration: registration, err: err})
+	}
+	return entries
+}
+
+// lookupAll looks up the valid entries with bounded concurrency
+func lookupAll(ctx context.Context, motClient mot.ClientInterface, vesClient ves.ClientInterface, entries []*entry, concurrency int) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		if e.err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			e.result = lookup.Fetch(ctx, motClient, vesClient, e.registration)
+		}(e)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"mot-bot/pkg/export"
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/render"
+)
+
+// Output formats
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatCSV      = "csv"
+	formatCSVTests = "csv-tests"
+)
+
+// jsonVehicle is a vehicle in the JSON output
+type jsonVehicle struct {
+	Input string `json:"input"`
+	*export.Vehicle
+	Error string `json:"error,omitempty"`
+}
+
+// validFormat reports whether format is one of the output formats
+func validFormat(format string) bool {
+	switch format {
+	case formatText, formatJSON, formatCSV, formatCSVTests:
+		return true
+	default:
+		return false
+	}
+}
+
+// write writes the looked up entries in the given format
+func write(w io.Writer, format string, entries []*entry, now time.Time) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, entries, now)
+	case formatCSV:
+		summaries := make([]export.Summary, len(entries))
+		for i, e := range entries {
+			if e.err != nil {
+				summaries[i] = export.Summary{Input: e.input, Error: e.err.Error()}
+				continue
+			}
+			summaries[i] = export.NewSummary(e.input, e.result)
+		}
+		return export.SummaryCSV(w, summaries)
+	case formatCSVTests:
+		var results []*lookup.Result
+		for _, e := range entries {
+			if e.err == nil {
+				results = append(results, e.result)
+			}
+		}
+		return export.CSV(w, results...)
+	default:
+		return writeText(w, entries)
+	}
+}
+
+// writeJSON writes the raw data of every vehicle as a JSON array
+func writeJSON(w io.Writer, entries []*entry, now time.Time) error {
+	vehicles := make([]jsonVehicle, len(entries))
+	for i, e := range entries {
+		vehicles[i].Input = e.input
+		if e.err != nil {
+			vehicles[i].Error = e.err.Error()
+			continue
+		}
+		v := export.NewVehicle(e.result, now)
+		vehicles[i].Vehicle = &v
+		if err := e.result.Err(); err != nil {
+			vehicles[i].Error = err.Error()
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(vehicles); err != nil {
+		return fmt.Errorf("failed to encode vehicles: %w", err)
+	}
+	return nil
+}
+
+// writeText writes every section the bot shows for each vehicle, as plain
+// text for reading in a terminal
+func writeText(w io.Writer, entries []*entry) error {
+	var sb strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("=== %s ===\n", e.input))
+
+		if e.err != nil {
+			sb.WriteString(fmt.Sprintf("Error: %v\n", e.err))
+			continue
+		}
+		if err := e.result.Err(); err != nil {
+			sb.WriteString(fmt.Sprintf("Error: %v\n", err))
+			continue
+		}
+		sb.WriteString(render.Full(e.result, render.Plain))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/ves"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEntries() []*entry {
+	entries := parseEntries([]string{"ab12 cde", "not a plate", "XY99ZZZ"})
+	entries[0].result = &lookup.Result{
+		Registration: "AB12CDE",
+		MOT: &mot.VehicleResponse{
+			Registration: "AB12CDE",
+			Make:         "FORD",
+			Model:        "FOCUS",
+			MotTests:     []mot.MotTest{{TestResult: "PASSED", OdometerValue: "80000", OdometerUnit: "MI"}},
+		},
+		VES: &ves.Vehicle{RegistrationNumber: "AB12CDE", TaxStatus: "Taxed"},
+	}
+	entries[2].result = &lookup.Result{
+		Registration: "XY99ZZZ",
+		MOTErr:       errors.New("MOT API error: unavailable"),
+		VESErr:       errors.New("VES API error: unavailable"),
+	}
+	return entries
+}
+
+func TestParseEntries(t *testing.T) {
+	entries := parseEntries([]string{"ab12 cde", "not a plate"})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "AB12CDE", entries[0].registration)
+	assert.NoError(t, entries[0].err)
+	assert.Error(t, entries[1].err)
+}
+
+func TestEntry_Failed(t *testing.T) {
+	entries := testEntries()
+	assert.False(t, entries[0].failed())
+	assert.True(t, entries[1].failed())
+	assert.True(t, entries[2].failed())
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, write(&buf, formatJSON, testEntries(), time.Now()))
+
+	var vehicles []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &vehicles))
+	require.Len(t, vehicles, 3)
+
+	assert.Equal(t, "ab12 cde", vehicles[0]["input"])
+	assert.Equal(t, "AB12CDE", vehicles[0]["registration"])
+	assert.NotNil(t, vehicles[0]["mot"])
+	assert.NotContains(t, vehicles[0], "error")
+
+	assert.Equal(t, "not a plate", vehicles[1]["input"])
+	assert.NotContains(t, vehicles[1], "registration")
+	assert.NotEmpty(t, vehicles[1]["error"])
+
+	assert.Contains(t, vehicles[2]["error"], "VES API error")
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, write(&buf, formatCSV, testEntries(), time.Now()))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 4)
+	assert.Equal(t, "ab12 cde,AB12CDE,FORD,FOCUS,PASSED,,Taxed,,", string(lines[1]))
+}
+
+func TestWrite_Text(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, write(&buf, formatText, testEntries(), time.Now()))
+
+	text := buf.String()
+	assert.Contains(t, text, "=== ab12 cde ===\n🩺 Reliability")
+	assert.Contains(t, text, "🚗 Vehicle Information")
+	assert.Contains(t, text, "📊 Status: Taxed")
+	assert.Contains(t, text, "🌿 Clean Air Zones (estimate)")
+	assert.Contains(t, text, "🔎 Mileage Check")
+	assert.NotContains(t, text, "`", "plain text has no Markdown")
+	assert.Contains(t, text, "=== not a plate ===\nError:")
+	assert.Contains(t, text, "=== XY99ZZZ ===\nError:")
+}
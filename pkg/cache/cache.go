@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"mot-bot/pkg/db"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/ves"
+)
+
+// Cache sources
+const (
+	sourceMOT = "mot"
+	sourceVES = "ves"
+)
+
+// Store persists cached responses
+type Store interface {
+	GetCachedResponse(source, carPlate string) (*db.CachedResponse, error)
+	PutCachedResponse(source, carPlate string, data []byte, fetchedAt time.Time) error
+}
+
+type refreshKey struct{}
+
+// WithRefresh returns a context that makes the caching clients skip the cache
+// and fetch fresh data, which is then cached again
+func WithRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+func isRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
+
+// MOTClient caches the responses of a MOT client
+type MOTClient struct {
+	client mot.ClientInterface
+	store  Store
+	ttl    time.Duration
+}
+
+func NewMOTClient(client mot.ClientInterface, store Store, ttl time.Duration) *MOTClient {
+	return &MOTClient{
+		client: client,
+		store:  store,
+		ttl:    ttl,
+	}
+}
+
+func (c *MOTClient) GetVehicleByRegistration(ctx context.Context, registration string) (*mot.VehicleResponse, error) {
+	vehicle, fetchedAt, err := get(ctx, c.store, c.ttl, sourceMOT, registration, c.client.GetVehicleByRegistration)
+	if err != nil {
+		return nil, err
+	}
+	vehicle.FetchedAt = fetchedAt
+	return vehicle, nil
+}
+
+// VESClient caches the responses of a VES client
+type VESClient struct {
+	client ves.ClientInterface
+	store  Store
+	ttl    time.Duration
+}
+
+func NewVESClient(client ves.ClientInterface, store Store, ttl time.Duration) *VESClient {
+	return &VESClient{
+		client: client,
+		store:  store,
+		ttl:    ttl,
+	}
+}
+
+func (c *VESClient) GetVehicleByRegistration(ctx context.Context, registration string) (*ves.Vehicle, error) {
+	vehicle, fetchedAt, err := get(ctx, c.store, c.ttl, sourceVES, registration, c.client.GetVehicleByRegistration)
+	if err != nil {
+		return nil, err
+	}
+	vehicle.FetchedAt = fetchedAt
+	return vehicle, nil
+}
+
+// get returns the cached response for a plate if it is younger than ttl,
+// otherwise it fetches and caches a fresh one. Cache failures are logged and
+// never fail the lookup.
+func get[T any](ctx context.Context, store Store, ttl time.Duration, source, registration string,
+	fetch func(context.Context, string) (*T, error)) (*T, time.Time, error) {
+	if !isRefresh(ctx) {
+		cached, err := store.GetCachedResponse(source, registration)
+		if err != nil {
+			log.Printf("Failed to read %s cache for %s: %v", source, registration, err)
+		}
+		if cached != nil && time.Since(cached.FetchedAt) < ttl {
+			var value T
+			err := json.Unmarshal(cached.Data, &value)
+			if err == nil {
+				return &value, cached.FetchedAt, nil
+			}
+			log.Printf("Failed to decode %s cache for %s: %v", source, registration, err)
+		}
+	}
+
+	value, err := fetch(ctx, registration)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	fetchedAt := time.Now()
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Failed to encode %s response for %s: %v", source, registration, err)
+		return value, fetchedAt, nil
+	}
+	if err := store.PutCachedResponse(source, registration, data, fetchedAt); err != nil {
+		log.Printf("Failed to cache %s response for %s: %v", source, registration, err)
+	}
+
+	return value, fetchedAt, nil
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/db"
+	"mot-bot/pkg/mot"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	responses map[string]*db.CachedResponse
+}
+
+func (s *memoryStore) GetCachedResponse(source, carPlate string) (*db.CachedResponse, error) {
+	return s.responses[source+"/"+carPlate], nil
+}
+
+func (s *memoryStore) PutCachedResponse(source, carPlate string, data []byte, fetchedAt time.Time) error {
+	s.responses[source+"/"+carPlate] = &db.CachedResponse{Data: data, FetchedAt: fetchedAt}
+	return nil
+}
+
+type countingMOTClient struct {
+	calls int
+}
+
+func (c *countingMOTClient) GetVehicleByRegistration(ctx context.Context, registration string) (*mot.VehicleResponse, error) {
+	c.calls++
+	return &mot.VehicleResponse{Registration: registration, Make: "FORD", FetchedAt: time.Now()}, nil
+}
+
+func TestMOTClient(t *testing.T) {
+	store := &memoryStore{responses: map[string]*db.CachedResponse{}}
+	inner := &countingMOTClient{}
+	client := NewMOTClient(inner, store, time.Hour)
+	ctx := context.Background()
+
+	first, err := client.GetVehicleByRegistration(ctx, "AB12CDE")
+	require.NoError(t, err)
+	second, err := client.GetVehicleByRegistration(ctx, "AB12CDE")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, "FORD", second.Make)
+	assert.Equal(t, first.FetchedAt.Unix(), second.FetchedAt.Unix())
+
+	_, err = client.GetVehicleByRegistration(WithRefresh(ctx), "AB12CDE")
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestMOTClient_Expired(t *testing.T) {
+	store := &memoryStore{responses: map[string]*db.CachedResponse{
+		"mot/AB12CDE": {Data: []byte(`{"registration":"AB12CDE"}`), FetchedAt: time.Now().Add(-2 * time.Hour)},
+	}}
+	inner := &countingMOTClient{}
+	client := NewMOTClient(inner, store, time.Hour)
+
+	_, err := client.GetVehicleByRegistration(context.Background(), "AB12CDE")
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+}
@@ -0,0 +1,281 @@
+// Package chart draws simple PNG charts with the standard image packages, so
+// that the bot can send them without any external renderer.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	defaultWidth  = 800
+	defaultHeight = 450
+
+	marginLeft   = 90
+	marginRight  = 30
+	marginTop    = 60
+	marginBottom = 50
+
+	textScale = 2
+	yTicks    = 5
+	maxXTicks = 10
+)
+
+var (
+	backgroundColour = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	axisColour       = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	gridColour       = color.RGBA{0xe3, 0xe3, 0xe3, 0xff}
+	textColour       = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	lineColour       = color.RGBA{0x1f, 0x77, 0xb4, 0xff}
+	failedColour     = color.RGBA{0xd6, 0x27, 0x28, 0xff}
+	anomalyColour    = color.RGBA{0xff, 0x8c, 0x00, 0xff}
+)
+
+// ErrNoPoints is returned when a chart has nothing to draw
+var ErrNoPoints = errors.New("chart has no points")
+
+// Point is a value at a point in time
+type Point struct {
+	Time  time.Time
+	Value float64
+
+	// Failed points are drawn in red
+	Failed bool
+
+	// Anomaly points are circled in orange
+	Anomaly bool
+}
+
+// LineChart is a line chart of values over time with the points marked
+type LineChart struct {
+	Title  string
+	Points []Point
+
+	// Width and Height are the size of the image in pixels, 800x450 if zero
+	Width  int
+	Height int
+}
+
+// PNG renders the chart as a PNG image
+func (c LineChart) PNG() ([]byte, error) {
+	img, err := c.Draw()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Draw renders the chart as an image
+func (c LineChart) Draw() (*image.RGBA, error) {
+	if len(c.Points) == 0 {
+		return nil, ErrNoPoints
+	}
+
+	width, height := c.Width, c.Height
+	if width == 0 || height == 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+
+	points := make([]Point, len(c.Points))
+	copy(points, c.Points)
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Time.Before(points[j].Time)
+	})
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, backgroundColour)
+
+	plot := image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom)
+
+	// Value axis from zero to a round number above the highest value
+	maxValue := 0.0
+	for _, p := range points {
+		maxValue = math.Max(maxValue, p.Value)
+	}
+	step := niceStep(maxValue / yTicks)
+	top := step * math.Ceil(maxValue/step)
+	if top == 0 {
+		top = step
+	}
+
+	// Time axis padded so the first and last points aren't on the edges
+	start, end := points[0].Time, points[len(points)-1].Time
+	span := end.Sub(start)
+	if span < 365*24*time.Hour {
+		span = 365 * 24 * time.Hour
+	}
+	start = start.Add(-span / 20)
+	end = start.Add(span + span/10)
+
+	x := func(t time.Time) int {
+		return plot.Min.X + int(float64(plot.Dx())*float64(t.Sub(start))/float64(end.Sub(start)))
+	}
+	y := func(v float64) int {
+		return plot.Max.Y - int(float64(plot.Dy())*v/top)
+	}
+
+	// Grid and value labels
+	for v := 0.0; v <= top+step/2; v += step {
+		py := y(v)
+		hLine(img, plot.Min.X, plot.Max.X, py, gridColour)
+		label := formatValue(v, step)
+		drawText(img, plot.Min.X-10-textWidth(label, textScale), py-glyphHeight*textScale/2, label, textScale, textColour)
+	}
+
+	// Year labels
+	years := end.Year() - start.Year() + 1
+	yearStep := max(1, int(math.Ceil(float64(years)/maxXTicks)))
+	for year := start.Year() + 1; year <= end.Year(); year += yearStep {
+		px := x(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC))
+		if px < plot.Min.X || px > plot.Max.X {
+			continue
+		}
+		vLine(img, px, plot.Min.Y, plot.Max.Y, gridColour)
+		label := fmt.Sprintf("%d", year)
+		drawText(img, px-textWidth(label, textScale)/2, plot.Max.Y+12, label, textScale, textColour)
+	}
+
+	// Axes
+	hLine(img, plot.Min.X, plot.Max.X, plot.Max.Y, axisColour)
+	vLine(img, plot.Min.X, plot.Min.Y, plot.Max.Y, axisColour)
+
+	// Line and points
+	for i := 1; i < len(points); i++ {
+		drawLine(img, x(points[i-1].Time), y(points[i-1].Value), x(points[i].Time), y(points[i].Value), 3, lineColour)
+	}
+	for _, p := range points {
+		px, py := x(p.Time), y(p.Value)
+		if p.Anomaly {
+			drawRing(img, px, py, 11, 3, anomalyColour)
+		}
+		if p.Failed {
+			drawDisc(img, px, py, 6, failedColour)
+		} else {
+			drawDisc(img, px, py, 4, lineColour)
+		}
+	}
+
+	// Title and legend
+	drawText(img, marginLeft, 20, c.Title, textScale, textColour)
+	legendX := width - marginRight - textWidth("ANOMALY", textScale)
+	drawText(img, legendX, 12, "ANOMALY", textScale, textColour)
+	drawRing(img, legendX-14, 12+glyphHeight, 8, 2, anomalyColour)
+	legendX -= textWidth("FAILED TEST", textScale) + 40
+	drawText(img, legendX, 12, "FAILED TEST", textScale, textColour)
+	drawDisc(img, legendX-14, 12+glyphHeight, 6, failedColour)
+
+	return img, nil
+}
+
+// niceStep rounds a raw step up to 1, 2 or 5 times a power of ten
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// formatValue formats an axis value, abbreviating thousands as K when the step allows it
+func formatValue(v, step float64) string {
+	if step >= 1000 && v != 0 {
+		return fmt.Sprintf("%gK", v/1000)
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+// fillRect fills a rectangle, clipped to the image
+func fillRect(img *image.RGBA, x, y, w, h int, c color.Color) {
+	r := image.Rect(x, y, x+w, y+h).Intersect(img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			img.Set(px, py, c)
+		}
+	}
+}
+
+func hLine(img *image.RGBA, x1, x2, y int, c color.Color) {
+	fillRect(img, x1, y, x2-x1+1, 1, c)
+}
+
+func vLine(img *image.RGBA, x, y1, y2 int, c color.Color) {
+	fillRect(img, x, y1, 1, y2-y1+1, c)
+}
+
+// drawLine draws a line of the given thickness with Bresenham's algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1, thickness int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	half := thickness / 2
+
+	for {
+		fillRect(img, x0-half, y0-half, thickness, thickness, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawDisc draws a filled circle
+func drawDisc(img *image.RGBA, cx, cy, r int, c color.Color) {
+	for py := -r; py <= r; py++ {
+		for px := -r; px <= r; px++ {
+			if px*px+py*py <= r*r {
+				img.Set(cx+px, cy+py, c)
+			}
+		}
+	}
+}
+
+// drawRing draws a circle outline of the given thickness
+func drawRing(img *image.RGBA, cx, cy, r, thickness int, c color.Color) {
+	inner := r - thickness
+	for py := -r; py <= r; py++ {
+		for px := -r; px <= r; px++ {
+			d := px*px + py*py
+			if d <= r*r && d > inner*inner {
+				img.Set(cx+px, cy+py, c)
+			}
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,56 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineChart_PNG(t *testing.T) {
+	c := LineChart{
+		Title: "AB12CDE mileage",
+		Points: []Point{
+			{Time: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), Value: 30000},
+			{Time: time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC), Value: 10000},
+			{Time: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), Value: 20000, Failed: true},
+			{Time: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), Value: 25000, Anomaly: true},
+		},
+	}
+
+	data, err := c.PNG()
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, defaultWidth, img.Bounds().Dx())
+	assert.Equal(t, defaultHeight, img.Bounds().Dy())
+
+	// Both marker colours must be drawn
+	colours := map[[3]uint8]bool{}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			colours[[3]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}] = true
+		}
+	}
+	assert.True(t, colours[[3]uint8{failedColour.R, failedColour.G, failedColour.B}])
+	assert.True(t, colours[[3]uint8{anomalyColour.R, anomalyColour.G, anomalyColour.B}])
+}
+
+func TestLineChart_NoPoints(t *testing.T) {
+	_, err := LineChart{}.PNG()
+	assert.ErrorIs(t, err, ErrNoPoints)
+}
+
+func TestNiceStep(t *testing.T) {
+	assert.Equal(t, 1.0, niceStep(0))
+	assert.Equal(t, 20000.0, niceStep(14000))
+	assert.Equal(t, 50000.0, niceStep(26000))
+	assert.Equal(t, 100000.0, niceStep(60000))
+	assert.Equal(t, 0.5, niceStep(0.3))
+}
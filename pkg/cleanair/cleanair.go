@@ -0,0 +1,245 @@
+// Package cleanair estimates whether a vehicle meets the emission standards
+// of London's Ultra Low Emission Zone and the Clean Air Zones in England.
+//
+// The DVLA doesn't publish compliance, so the estimate is based on the fuel
+// type, the Euro status if the DVLA has one, and otherwise the date the
+// vehicle was first registered. The rule tables below hold the standards and
+// zones so they can be updated when the schemes change.
+package cleanair
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status is the outcome of a compliance estimate
+type Status string
+
+// Compliance estimates
+const (
+	StatusCompliant    Status = "likely compliant"
+	StatusNonCompliant Status = "likely non-compliant"
+	StatusUnknown      Status = "unknown"
+)
+
+// Fuel groups that the standards are defined for
+const (
+	FuelPetrol       = "petrol"
+	FuelDiesel       = "diesel"
+	FuelZeroEmission = "zero emission"
+)
+
+// Standard is the minimum Euro standard for a fuel group
+type Standard struct {
+	MinEuro int
+
+	// MandatoryFrom is when the standard became mandatory for new cars, so any car first registered since meets it
+	MandatoryFrom time.Time
+
+	// AvailableFrom is when the first cars meeting the standard were sold, so no car registered before meets it
+	AvailableFrom time.Time
+}
+
+// Standards are the minimum Euro standards for cars and vans shared by the London ULEZ and Clean Air Zones
+var Standards = map[string]Standard{
+	FuelPetrol: {
+		MinEuro:       4,
+		MandatoryFrom: time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC),
+		AvailableFrom: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+	},
+	FuelDiesel: {
+		MinEuro:       6,
+		MandatoryFrom: time.Date(2015, 9, 1, 0, 0, 0, 0, time.UTC),
+		AvailableFrom: time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC),
+	},
+}
+
+// MotorcycleStandard is the minimum Euro standard for motorcycles and other
+// L category vehicles of any fuel
+var MotorcycleStandard = Standard{
+	MinEuro:       3,
+	MandatoryFrom: time.Date(2007, 7, 1, 0, 0, 0, 0, time.UTC),
+	AvailableFrom: time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// Zone is a clean air scheme and the vehicle type approval categories it charges
+type Zone struct {
+	Name   string
+	Cities string
+
+	// Charged lists the type approval categories the zone charges. Nil means every vehicle type is charged.
+	Charged []string
+}
+
+// Zones are the schemes that vehicles are checked against
+var Zones = []Zone{
+	{Name: "London ULEZ", Cities: "London"},
+	{Name: "CAZ class D", Cities: "Birmingham, Bristol"},
+	{Name: "CAZ class C", Cities: "Bath, Bradford, Sheffield, Tyneside", Charged: []string{"M2", "M3", "N1", "N2", "N3"}},
+}
+
+// Vehicle holds the details the estimate is based on
+type Vehicle struct {
+	FuelType        string
+	EuroStatus      string
+	FirstRegistered time.Time
+
+	// TypeApproval is the type approval category, such as M1 for cars and N1 for vans. It may be empty.
+	TypeApproval string
+}
+
+// Assessment is the compliance estimate of a vehicle for a zone
+type Assessment struct {
+	Zone   Zone
+	Status Status
+	Reason string
+}
+
+// Assess estimates whether the vehicle meets the standards of every zone
+func Assess(v Vehicle) []Assessment {
+	assessments := make([]Assessment, 0, len(Zones))
+	for _, zone := range Zones {
+		if zone.Charged != nil && v.TypeApproval == "" {
+			assessments = append(assessments, Assessment{
+				Zone:   zone,
+				Status: StatusUnknown,
+				Reason: "The vehicle category is unknown, and only some categories are charged in this zone",
+			})
+			continue
+		}
+		if !zone.charges(v.TypeApproval) {
+			assessments = append(assessments, Assessment{
+				Zone:   zone,
+				Status: StatusCompliant,
+				Reason: fmt.Sprintf("%s vehicles are not charged in this zone", strings.ToUpper(v.TypeApproval)),
+			})
+			continue
+		}
+
+		status, reason := Check(v)
+		assessments = append(assessments, Assessment{Zone: zone, Status: status, Reason: reason})
+	}
+	return assessments
+}
+
+// charges reports whether the zone charges vehicles of the type approval category
+func (z Zone) charges(typeApproval string) bool {
+	if z.Charged == nil {
+		return true
+	}
+	for _, category := range z.Charged {
+		if strings.EqualFold(category, typeApproval) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check estimates whether the vehicle meets the emission standard for its
+// fuel, or the motorcycle standard for L category vehicles, with the reason
+func Check(v Vehicle) (Status, string) {
+	fuel := FuelGroup(v.FuelType)
+	switch fuel {
+	case "":
+		return StatusUnknown, "The fuel type is unknown"
+	case FuelZeroEmission:
+		return StatusCompliant, "Zero emission vehicles meet every standard"
+	}
+	standard := Standards[fuel]
+	if IsMotorcycle(v.TypeApproval) {
+		standard = MotorcycleStandard
+		fuel = "motorcycle"
+	}
+
+	if euro, ok := ParseEuroStatus(v.EuroStatus); ok {
+		if euro >= standard.MinEuro {
+			return StatusCompliant, fmt.Sprintf("%s with Euro %d, the standard is Euro %d", capitalise(fuel), euro, standard.MinEuro)
+		}
+		return StatusNonCompliant, fmt.Sprintf("%s with Euro %d, the standard is Euro %d", capitalise(fuel), euro, standard.MinEuro)
+	}
+
+	if v.FirstRegistered.IsZero() {
+		return StatusUnknown, "Neither the Euro status nor the first registration date is known"
+	}
+
+	registered := v.FirstRegistered.Format("01.2006")
+	switch {
+	case !v.FirstRegistered.Before(standard.MandatoryFrom):
+		return StatusCompliant, fmt.Sprintf("%s first registered %s, after Euro %d became mandatory", capitalise(fuel), registered, standard.MinEuro)
+	case v.FirstRegistered.Before(standard.AvailableFrom):
+		return StatusNonCompliant, fmt.Sprintf("%s first registered %s, before Euro %d vehicles were sold", capitalise(fuel), registered, standard.MinEuro)
+	default:
+		return StatusUnknown, fmt.Sprintf("%s first registered %s, when some vehicles met Euro %d; check with the manufacturer", capitalise(fuel), registered, standard.MinEuro)
+	}
+}
+
+// IsMotorcycle reports whether a type approval category is an L category
+// vehicle, such as a moped, motorcycle or quadricycle
+func IsMotorcycle(typeApproval string) bool {
+	return strings.HasPrefix(strings.ToUpper(typeApproval), "L")
+}
+
+// Overall returns the least favourable status of the assessments, so a
+// vehicle is only compliant overall if it is compliant in every zone
+func Overall(assessments []Assessment) Status {
+	overall := StatusCompliant
+	for _, a := range assessments {
+		switch {
+		case a.Status == StatusNonCompliant:
+			return StatusNonCompliant
+		case a.Status == StatusUnknown:
+			overall = StatusUnknown
+		}
+	}
+	return overall
+}
+
+// FuelGroup returns the fuel group a DVLA or DVSA fuel type is assessed as,
+// or an empty string if it isn't known. Hybrids are assessed by their engine
+// and bi-fuel gas vehicles as petrol.
+func FuelGroup(fuelType string) string {
+	fuel := strings.ToUpper(fuelType)
+	switch {
+	case fuel == "":
+		return ""
+	case strings.Contains(fuel, "DIESEL"):
+		return FuelDiesel
+	case strings.Contains(fuel, "PETROL"), strings.Contains(fuel, "HYBRID"),
+		strings.Contains(fuel, "GAS"), strings.Contains(fuel, "LPG"):
+		return FuelPetrol
+	case strings.Contains(fuel, "ELECTRIC"), strings.Contains(fuel, "HYDROGEN"), strings.Contains(fuel, "FUEL CELL"):
+		return FuelZeroEmission
+	default:
+		return ""
+	}
+}
+
+var euroStatusPattern = regexp.MustCompile(`(?i)^\s*(?:EURO)?\s*(?:([0-9])|(VI|V|IV|III|II|I)\b)`)
+
+// romanEuroStatuses maps the roman numerals used for heavy vehicle Euro standards
+var romanEuroStatuses = map[string]int{"I": 1, "II": 2, "III": 3, "IV": 4, "V": 5, "VI": 6}
+
+// ParseEuroStatus returns the Euro standard number of a DVLA Euro status such
+// as "EURO 6", "Euro 6d" or "EURO VI"
+func ParseEuroStatus(status string) (int, bool) {
+	match := euroStatusPattern.FindStringSubmatch(strings.ReplaceAll(status, "_", " "))
+	if match == nil {
+		return 0, false
+	}
+	if match[1] != "" {
+		n, _ := strconv.Atoi(match[1])
+		return n, n > 0
+	}
+	n, ok := romanEuroStatuses[strings.ToUpper(match[2])]
+	return n, ok
+}
+
+func capitalise(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
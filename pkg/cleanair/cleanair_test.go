@@ -0,0 +1,125 @@
+package cleanair
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEuroStatus(t *testing.T) {
+	cases := map[string]int{
+		"EURO 6":   6,
+		"Euro 6d":  6,
+		"EURO6 AD": 6,
+		"euro 4":   4,
+		"EURO VI":  6,
+		"Euro IV":  4,
+		"5":        5,
+	}
+	for status, want := range cases {
+		got, ok := ParseEuroStatus(status)
+		assert.True(t, ok, status)
+		assert.Equal(t, want, got, status)
+	}
+
+	for _, status := range []string{"", "UNKNOWN", "EURO"} {
+		_, ok := ParseEuroStatus(status)
+		assert.False(t, ok, status)
+	}
+}
+
+func TestFuelGroup(t *testing.T) {
+	cases := map[string]string{
+		"PETROL":                  FuelPetrol,
+		"Hybrid Electric (Clean)": FuelPetrol,
+		"GAS BI-FUEL":             FuelPetrol,
+		"DIESEL":                  FuelDiesel,
+		"Electric Diesel":         FuelDiesel,
+		"ELECTRICITY":             FuelZeroEmission,
+		"Electric":                FuelZeroEmission,
+		"STEAM":                   "",
+		"":                        "",
+	}
+	for fuel, want := range cases {
+		assert.Equal(t, want, FuelGroup(fuel), fuel)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	date := func(year int, month time.Month) time.Time {
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name    string
+		vehicle Vehicle
+		want    Status
+	}{
+		{"petrol euro status", Vehicle{FuelType: "PETROL", EuroStatus: "EURO 4"}, StatusCompliant},
+		{"diesel euro status", Vehicle{FuelType: "DIESEL", EuroStatus: "EURO 5"}, StatusNonCompliant},
+		{"euro status wins over date", Vehicle{FuelType: "DIESEL", EuroStatus: "EURO 6", FirstRegistered: date(2015, 1)}, StatusCompliant},
+		{"petrol after mandatory", Vehicle{FuelType: "PETROL", FirstRegistered: date(2006, 1)}, StatusCompliant},
+		{"petrol in between", Vehicle{FuelType: "PETROL", FirstRegistered: date(2003, 6)}, StatusUnknown},
+		{"petrol before available", Vehicle{FuelType: "PETROL", FirstRegistered: date(1999, 6)}, StatusNonCompliant},
+		{"diesel after mandatory", Vehicle{FuelType: "DIESEL", FirstRegistered: date(2016, 2)}, StatusCompliant},
+		{"diesel before available", Vehicle{FuelType: "DIESEL", FirstRegistered: date(2012, 3)}, StatusNonCompliant},
+		{"electric", Vehicle{FuelType: "ELECTRICITY"}, StatusCompliant},
+		{"unknown fuel", Vehicle{FuelType: "STEAM", EuroStatus: "EURO 6"}, StatusUnknown},
+		{"nothing known", Vehicle{FuelType: "PETROL"}, StatusUnknown},
+		{"motorcycle euro 3", Vehicle{FuelType: "PETROL", EuroStatus: "EURO 3", TypeApproval: "L3e"}, StatusCompliant},
+		{"motorcycle euro 2", Vehicle{FuelType: "PETROL", EuroStatus: "EURO 2", TypeApproval: "L3"}, StatusNonCompliant},
+		{"motorcycle after mandatory", Vehicle{FuelType: "PETROL", FirstRegistered: date(2008, 3), TypeApproval: "L3"}, StatusCompliant},
+		{"motorcycle in between", Vehicle{FuelType: "PETROL", FirstRegistered: date(2006, 9), TypeApproval: "L1"}, StatusUnknown},
+		{"diesel motorcycle", Vehicle{FuelType: "DIESEL", EuroStatus: "EURO 4", TypeApproval: "L5"}, StatusCompliant},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, reason := Check(tc.vehicle)
+			assert.Equal(t, tc.want, status)
+			assert.NotEmpty(t, reason)
+		})
+	}
+}
+
+func TestAssess(t *testing.T) {
+	car := Vehicle{FuelType: "DIESEL", EuroStatus: "EURO 5", TypeApproval: "M1"}
+
+	assessments := Assess(car)
+
+	require.Len(t, assessments, len(Zones))
+	assert.Equal(t, "London ULEZ", assessments[0].Zone.Name)
+	assert.Equal(t, StatusNonCompliant, assessments[0].Status)
+	assert.Equal(t, StatusNonCompliant, assessments[1].Status)
+	// Class C zones don't charge cars
+	assert.Equal(t, StatusCompliant, assessments[2].Status)
+
+	van := car
+	van.TypeApproval = "N1"
+	assert.Equal(t, StatusNonCompliant, Assess(van)[2].Status)
+}
+
+func TestAssess_UnknownCategory(t *testing.T) {
+	assessments := Assess(Vehicle{FuelType: "DIESEL", EuroStatus: "EURO 5"})
+
+	assert.Equal(t, StatusNonCompliant, assessments[0].Status)
+	// Class C zones only charge some categories, so a missing one can't be assumed
+	assert.Equal(t, StatusUnknown, assessments[2].Status)
+	assert.Contains(t, assessments[2].Reason, "category is unknown")
+}
+
+func TestOverall(t *testing.T) {
+	assess := func(statuses ...Status) []Assessment {
+		var assessments []Assessment
+		for _, status := range statuses {
+			assessments = append(assessments, Assessment{Status: status})
+		}
+		return assessments
+	}
+
+	assert.Equal(t, StatusCompliant, Overall(assess(StatusCompliant, StatusCompliant)))
+	assert.Equal(t, StatusUnknown, Overall(assess(StatusCompliant, StatusUnknown)))
+	assert.Equal(t, StatusNonCompliant, Overall(assess(StatusUnknown, StatusNonCompliant, StatusCompliant)))
+}
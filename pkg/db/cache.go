@@ -0,0 +1,76 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CachedResponse is an upstream API response stored for later lookups
+type CachedResponse struct {
+	Data      []byte
+	FetchedAt time.Time
+}
+
+func createCacheTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS response_cache (
+		source TEXT NOT NULL,
+		car_plate TEXT NOT NULL,
+		fetched_at DATETIME NOT NULL,
+		data BLOB NOT NULL,
+		PRIMARY KEY (source, car_plate)
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// GetCachedResponse returns the stored response of a source for a plate, or nil if there is none
+func (l *Logger) GetCachedResponse(source, carPlate string) (*CachedResponse, error) {
+	query := `SELECT data, fetched_at FROM response_cache WHERE source = ? AND car_plate = ?`
+
+	var cached CachedResponse
+	err := l.db.QueryRow(query, source, carPlate).Scan(&cached.Data, &cached.FetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached response: %w", err)
+	}
+
+	return &cached, nil
+}
+
+// PutCachedResponse stores the response of a source for a plate, replacing any previous one
+func (l *Logger) PutCachedResponse(source, carPlate string, data []byte, fetchedAt time.Time) error {
+	query := `
+	INSERT INTO response_cache (source, car_plate, fetched_at, data)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(source, car_plate) DO UPDATE SET
+		fetched_at = excluded.fetched_at,
+		data = excluded.data`
+
+	if _, err := l.db.Exec(query, source, carPlate, fetchedAt.UTC(), data); err != nil {
+		return fmt.Errorf("failed to cache response: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCachedResponsesBefore removes the responses fetched before a time and
+// returns how many were removed
+func (l *Logger) DeleteCachedResponsesBefore(before time.Time) (int64, error) {
+	res, err := l.db.Exec(`DELETE FROM response_cache WHERE fetched_at < ?`, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cached responses: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete cached responses: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteCachedResponsesBefore(t *testing.T) {
+	logger := newTestLogger(t)
+	now := time.Date(2030, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, logger.PutCachedResponse("mot", "AB12CDE", []byte(`{}`), now.Add(-2*time.Hour)))
+	require.NoError(t, logger.PutCachedResponse("mot", "XY99ZZZ", []byte(`{}`), now.Add(-time.Minute)))
+
+	deleted, err := logger.DeleteCachedResponsesBefore(now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	cached, err := logger.GetCachedResponse("mot", "AB12CDE")
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+
+	cached, err = logger.GetCachedResponse("mot", "XY99ZZZ")
+	require.NoError(t, err)
+	assert.NotNil(t, cached)
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GarageVehicle is a vehicle saved to a user's garage
+type GarageVehicle struct {
+	ID        int64
+	CreatedAt time.Time
+	UserID    int64
+	CarPlate  string
+	Name      string
+}
+
+func createGarageTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS garage (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at DATETIME NOT NULL,
+		user_id INTEGER NOT NULL,
+		car_plate TEXT NOT NULL,
+		name TEXT NOT NULL,
+		UNIQUE(user_id, car_plate)
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// AddGarageVehicle saves a vehicle to a user's garage, renaming it if it is already there
+func (l *Logger) AddGarageVehicle(userID int64, carPlate, name string) error {
+	query := `
+	INSERT INTO garage (created_at, user_id, car_plate, name)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(user_id, car_plate) DO UPDATE SET name = excluded.name`
+
+	_, err := l.db.Exec(query, time.Now().UTC(), userID, carPlate, name)
+	if err != nil {
+		return fmt.Errorf("failed to add garage vehicle: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveGarageVehicle removes a vehicle from a user's garage. It reports false
+// if the vehicle was not in the garage.
+func (l *Logger) RemoveGarageVehicle(userID int64, carPlate string) (bool, error) {
+	res, err := l.db.Exec(`DELETE FROM garage WHERE user_id = ? AND car_plate = ?`, userID, carPlate)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove garage vehicle: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove garage vehicle: %w", err)
+	}
+
+	return n == 1, nil
+}
+
+// GetGarage returns the vehicles in a user's garage in the order they were added
+func (l *Logger) GetGarage(userID int64) ([]GarageVehicle, error) {
+	query := `
+	SELECT id, created_at, user_id, car_plate, name
+	FROM garage
+	WHERE user_id = ?
+	ORDER BY id`
+
+	rows, err := l.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get garage: %w", err)
+	}
+	defer rows.Close()
+
+	var vehicles []GarageVehicle
+	for rows.Next() {
+		var v GarageVehicle
+		if err := rows.Scan(&v.ID, &v.CreatedAt, &v.UserID, &v.CarPlate, &v.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan garage vehicle: %w", err)
+		}
+		vehicles = append(vehicles, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read garage: %w", err)
+	}
+
+	return vehicles, nil
+}
@@ -33,6 +33,10 @@ func NewLogger(dbPath string) (*Logger, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// SQLite allows a single writer, so serialise access instead of failing
+	// with "database is locked" when updates are handled concurrently
+	db.SetMaxOpenConns(1)
+
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
@@ -41,6 +45,18 @@ func NewLogger(dbPath string) (*Logger, error) {
 		return nil, fmt.Errorf("failed to create table: %w", err)
 	}
 
+	if err := createSubscriptionsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create subscriptions table: %w", err)
+	}
+
+	if err := createGarageTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create garage table: %w", err)
+	}
+
+	if err := createCacheTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create cache table: %w", err)
+	}
+
 	return &Logger{db: db}, nil
 }
 
@@ -99,6 +115,52 @@ func (l *Logger) GetStats() (*Stats, error) {
 	return stats, nil
 }
 
+// GetRequestLogs returns the requests logged from from until before to, oldest first
+func (l *Logger) GetRequestLogs(from, to time.Time) ([]RequestLog, error) {
+	var logs []RequestLog
+	err := l.EachRequestLog(from, to, func(r RequestLog) error {
+		logs = append(logs, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// EachRequestLog calls fn for each request logged from from until before to,
+// oldest first, without loading them all at once. It stops with the error fn
+// returns, if any.
+func (l *Logger) EachRequestLog(from, to time.Time, fn func(RequestLog) error) error {
+	query := `
+	SELECT id, timestamp, user_id, username, car_plate, response
+	FROM request_logs
+	WHERE timestamp >= ? AND timestamp < ?
+	ORDER BY timestamp, id`
+
+	rows, err := l.db.Query(query, from.UTC(), to.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to get request logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r RequestLog
+		if err := rows.Scan(&r.ID, &r.Timestamp, &r.UserID, &r.Username, &r.CarPlate, &r.Response); err != nil {
+			return fmt.Errorf("failed to scan request log: %w", err)
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read request logs: %w", err)
+	}
+
+	return nil
+}
+
 func (l *Logger) Close() error {
 	return l.db.Close()
 }
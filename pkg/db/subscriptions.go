@@ -0,0 +1,157 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Subscription kinds
+const (
+	SubscriptionMOT = "mot"
+	SubscriptionTax = "tax"
+)
+
+// Subscription is a reminder to notify a chat ahead of a vehicle's MOT or tax due date
+type Subscription struct {
+	ID        int64
+	CreatedAt time.Time
+	UserID    int64
+	ChatID    int64
+	CarPlate  string
+	Kind      string
+	DueDate   time.Time
+	NotifyAt  time.Time
+}
+
+func createSubscriptionsTable(db *sql.DB) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS subscriptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at DATETIME NOT NULL,
+		user_id INTEGER NOT NULL,
+		chat_id INTEGER NOT NULL,
+		car_plate TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		due_date DATETIME NOT NULL,
+		notify_at DATETIME NOT NULL,
+		sent INTEGER NOT NULL DEFAULT 0,
+		UNIQUE(user_id, car_plate, kind)
+	)`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// AddSubscription stores a reminder, replacing any existing one for the same user, plate and kind
+func (l *Logger) AddSubscription(userID, chatID int64, carPlate, kind string, dueDate, notifyAt time.Time) error {
+	query := `
+	INSERT INTO subscriptions (created_at, user_id, chat_id, car_plate, kind, due_date, notify_at, sent)
+	VALUES (?, ?, ?, ?, ?, ?, ?, 0)
+	ON CONFLICT(user_id, car_plate, kind) DO UPDATE SET
+		created_at = excluded.created_at,
+		chat_id = excluded.chat_id,
+		due_date = excluded.due_date,
+		notify_at = excluded.notify_at,
+		sent = 0`
+
+	_, err := l.db.Exec(query, time.Now().UTC(), userID, chatID, carPlate, kind, dueDate.UTC(), notifyAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to add subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserSubscriptions returns the pending reminders of a user, soonest first
+func (l *Logger) GetUserSubscriptions(userID int64) ([]Subscription, error) {
+	query := `
+	SELECT id, created_at, user_id, chat_id, car_plate, kind, due_date, notify_at
+	FROM subscriptions
+	WHERE user_id = ? AND sent = 0
+	ORDER BY due_date, car_plate, kind`
+
+	rows, err := l.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSubscriptions(rows)
+}
+
+// GetDueSubscriptions returns the pending reminders that should be sent at or before now
+func (l *Logger) GetDueSubscriptions(now time.Time) ([]Subscription, error) {
+	query := `
+	SELECT id, created_at, user_id, chat_id, car_plate, kind, due_date, notify_at
+	FROM subscriptions
+	WHERE sent = 0 AND notify_at <= ?
+	ORDER BY notify_at`
+
+	rows, err := l.db.Query(query, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSubscriptions(rows)
+}
+
+// ClaimSubscription marks a reminder as sent. It reports false if the reminder
+// was already claimed or no longer exists, so it is never sent twice.
+func (l *Logger) ClaimSubscription(id int64) (bool, error) {
+	res, err := l.db.Exec(`UPDATE subscriptions SET sent = 1 WHERE id = ? AND sent = 0`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim subscription: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim subscription: %w", err)
+	}
+
+	return n == 1, nil
+}
+
+// ReleaseSubscription marks a claimed reminder as pending again, so it is
+// retried by the next check after sending it failed
+func (l *Logger) ReleaseSubscription(id int64) error {
+	if _, err := l.db.Exec(`UPDATE subscriptions SET sent = 0 WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to release subscription: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSubscription removes a reminder owned by the given user. It reports
+// false if there was no such reminder.
+func (l *Logger) DeleteSubscription(userID, id int64) (bool, error) {
+	res, err := l.db.Exec(`DELETE FROM subscriptions WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete subscription: %w", err)
+	}
+
+	return n == 1, nil
+}
+
+func scanSubscriptions(rows *sql.Rows) ([]Subscription, error) {
+	var subs []Subscription
+	for rows.Next() {
+		var s Subscription
+		if err := rows.Scan(&s.ID, &s.CreatedAt, &s.UserID, &s.ChatID, &s.CarPlate, &s.Kind, &s.DueDate, &s.NotifyAt); err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %w", err)
+		}
+		subs = append(subs, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) *Logger {
+	t.Helper()
+	logger, err := NewLogger(t.TempDir() + "/test.db")
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.Close() })
+	return logger
+}
+
+func TestAddSubscription_ReplacesExisting(t *testing.T) {
+	logger := newTestLogger(t)
+	due := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, logger.AddSubscription(1, 10, "AB12CDE", SubscriptionMOT, due, due.AddDate(0, 0, -14)))
+	require.NoError(t, logger.AddSubscription(1, 10, "AB12CDE", SubscriptionTax, due, due.AddDate(0, 0, -14)))
+	require.NoError(t, logger.AddSubscription(1, 11, "AB12CDE", SubscriptionMOT, due.AddDate(1, 0, 0), due.AddDate(1, 0, -14)))
+
+	subs, err := logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+
+	// Soonest first, and the MOT reminder was replaced by the later one
+	assert.Equal(t, SubscriptionTax, subs[0].Kind)
+	assert.Equal(t, SubscriptionMOT, subs[1].Kind)
+	assert.Equal(t, int64(11), subs[1].ChatID)
+	assert.True(t, subs[1].DueDate.Equal(due.AddDate(1, 0, 0)))
+
+	others, err := logger.GetUserSubscriptions(2)
+	require.NoError(t, err)
+	assert.Empty(t, others)
+}
+
+func TestClaimSubscription_SendsOnce(t *testing.T) {
+	logger := newTestLogger(t)
+	now := time.Date(2030, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, logger.AddSubscription(1, 10, "AB12CDE", SubscriptionMOT, now.AddDate(0, 0, 10), now.Add(-time.Hour)))
+	require.NoError(t, logger.AddSubscription(1, 10, "XY99ZZZ", SubscriptionMOT, now.AddDate(0, 1, 0), now.AddDate(0, 0, 7)))
+
+	due, err := logger.GetDueSubscriptions(now)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "AB12CDE", due[0].CarPlate)
+
+	claimed, err := logger.ClaimSubscription(due[0].ID)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = logger.ClaimSubscription(due[0].ID)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// A claimed reminder is neither due nor pending any more
+	due, err = logger.GetDueSubscriptions(now)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	subs, err := logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "XY99ZZZ", subs[0].CarPlate)
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	logger := newTestLogger(t)
+	due := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, logger.AddSubscription(1, 10, "AB12CDE", SubscriptionMOT, due, due.AddDate(0, 0, -14)))
+
+	subs, err := logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+
+	// Only the owner can cancel a reminder
+	deleted, err := logger.DeleteSubscription(2, subs[0].ID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = logger.DeleteSubscription(1, subs[0].ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	claimed, err := logger.ClaimSubscription(subs[0].ID)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+}
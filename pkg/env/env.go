@@ -0,0 +1,73 @@
+// Package env reads the settings shared by the bot and the command line tool
+// from environment variables. Invalid values stop the program.
+package env
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"mot-bot/pkg/upstream"
+)
+
+// Int returns a non-negative integer environment variable, or def if it is not set
+func Int(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("%s must be a non-negative integer, got %q", name, v)
+	}
+	return n
+}
+
+// Float returns a non-negative number environment variable, or def if it is not set
+func Float(name string, def float64) float64 {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		log.Fatalf("%s must be a non-negative number, got %q", name, v)
+	}
+	return f
+}
+
+// Duration returns a non-negative duration environment variable such as
+// 30m, or def if it is not set
+func Duration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Fatalf("%s must be a non-negative duration such as 30m, got %q", name, v)
+	}
+	return d
+}
+
+// Required returns an environment variable, stopping the program if it is not set
+func Required(name string) string {
+	v := os.Getenv(name)
+	if v == "" {
+		log.Fatalf("%s environment variable is not set", name)
+	}
+	return v
+}
+
+// Policy returns the retry and rate limits of an API from HTTP_MAX_RETRIES
+// and the <prefix>_RATE_LIMIT and <prefix>_RATE_BURST variables
+func Policy(prefix string, rate float64, burst int) upstream.Policy {
+	return upstream.Policy{
+		MaxRetries: Int("HTTP_MAX_RETRIES", 3),
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   5 * time.Second,
+		Rate:       Float(prefix+"_RATE_LIMIT", rate),
+		Burst:      Int(prefix+"_RATE_BURST", burst),
+	}
+}
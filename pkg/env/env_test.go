@@ -0,0 +1,35 @@
+package env
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaults(t *testing.T) {
+	t.Setenv("TEST_INT", "")
+	assert.Equal(t, 7, Int("TEST_INT", 7))
+	assert.Equal(t, 1.5, Float("TEST_FLOAT_UNSET", 1.5))
+	assert.Equal(t, time.Minute, Duration("TEST_DURATION_UNSET", time.Minute))
+}
+
+func TestValues(t *testing.T) {
+	t.Setenv("TEST_INT", "12")
+	t.Setenv("TEST_FLOAT", "2.5")
+	t.Setenv("TEST_DURATION", "90s")
+	assert.Equal(t, 12, Int("TEST_INT", 7))
+	assert.Equal(t, 2.5, Float("TEST_FLOAT", 1.5))
+	assert.Equal(t, 90*time.Second, Duration("TEST_DURATION", time.Minute))
+}
+
+func TestPolicy(t *testing.T) {
+	t.Setenv("HTTP_MAX_RETRIES", "5")
+	t.Setenv("MOT_RATE_LIMIT", "3")
+	t.Setenv("MOT_RATE_BURST", "")
+
+	p := Policy("MOT", 15, 10)
+	assert.Equal(t, 5, p.MaxRetries)
+	assert.Equal(t, 3.0, p.Rate)
+	assert.Equal(t, 10, p.Burst)
+}
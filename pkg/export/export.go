@@ -0,0 +1,283 @@
+// Package export converts lookup results into CSV and JSON files for
+// spreadsheets and other tools.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/ves"
+)
+
+// Formats that a lookup can be exported in
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// csvDateLayout is ISO 8601, which spreadsheets recognise as a date
+const csvDateLayout = "2006-01-02"
+
+// CSVHeader is the header row of the CSV export
+var CSVHeader = []string{
+	"registration",
+	"make",
+	"model",
+	"fuel_type",
+	"colour",
+	"first_used",
+	"tax_status",
+	"tax_due",
+	"mot_due",
+	"test_date",
+	"test_result",
+	"expiry_date",
+	"odometer_value",
+	"odometer_unit",
+	"odometer_result",
+	"test_number",
+	"data_source",
+	"defect_type",
+	"defect_dangerous",
+	"defect_text",
+}
+
+// Vehicle is the combined data of a lookup as exported to JSON
+type Vehicle struct {
+	Registration string               `json:"registration"`
+	ExportedAt   time.Time            `json:"exportedAt"`
+	MOT          *mot.VehicleResponse `json:"mot"`
+	VES          *ves.Vehicle         `json:"ves"`
+	MOTError     string               `json:"motError,omitempty"`
+	VESError     string               `json:"vesError,omitempty"`
+}
+
+// NewVehicle returns the exported form of a lookup result
+func NewVehicle(result *lookup.Result, exportedAt time.Time) Vehicle {
+	v := Vehicle{
+		Registration: result.Registration,
+		ExportedAt:   exportedAt.UTC(),
+		MOT:          result.MOT,
+		VES:          result.VES,
+	}
+	if result.MOTErr != nil {
+		v.MOTError = result.MOTErr.Error()
+	}
+	if result.VESErr != nil {
+		v.VESError = result.VESErr.Error()
+	}
+	return v
+}
+
+// JSON writes the raw MOT and DVLA data of a lookup as indented JSON
+func JSON(w io.Writer, result *lookup.Result, exportedAt time.Time) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(NewVehicle(result, exportedAt)); err != nil {
+		return fmt.Errorf("failed to encode vehicle: %w", err)
+	}
+	return nil
+}
+
+// CSV writes the lookups with one row per MOT test and defect. Tests without
+// defects get a single row with empty defect columns, and vehicles without
+// tests a single row with only the vehicle columns.
+func CSV(w io.Writer, results ...*lookup.Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, result := range results {
+		for _, row := range csvRows(result) {
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// csvRows returns the CSV rows of a single lookup
+func csvRows(result *lookup.Result) [][]string {
+	vehicle := []string{result.Registration, "", "", "", "", "", "", "", ""}
+	if result.MOT != nil {
+		vehicle[1] = result.MOT.Make
+		vehicle[2] = result.MOT.Model
+		vehicle[3] = result.MOT.FuelType
+		vehicle[4] = result.MOT.PrimaryColour
+		vehicle[5] = csvDate(result.MOT.FirstUsedDate.Time)
+		if due, ok := result.MOT.MOTDueDate(); ok {
+			vehicle[8] = csvDate(due)
+		}
+	}
+	if result.VES != nil {
+		if result.MOT == nil {
+			vehicle[1] = result.VES.Make
+			vehicle[3] = result.VES.FuelType
+			vehicle[4] = result.VES.Colour
+			vehicle[5] = csvDate(result.VES.MonthOfFirstRegistration.Time)
+			vehicle[8] = csvDate(result.VES.MotExpiryDate.Time)
+		}
+		vehicle[6] = result.VES.TaxStatus
+		vehicle[7] = csvDate(result.VES.TaxDueDate.Time)
+	}
+
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		return [][]string{row(vehicle, nil, nil)}
+	}
+
+	var rows [][]string
+	for _, test := range result.MOT.MotTests {
+		testColumns := []string{
+			csvDate(test.CompletedDate.Time),
+			test.TestResult,
+			csvDate(test.ExpiryDate.Time),
+			test.OdometerValue,
+			test.OdometerUnit,
+			test.OdometerResultType,
+			test.MotTestNumber,
+			test.DataSource,
+		}
+		if len(test.Defects) == 0 {
+			rows = append(rows, row(vehicle, testColumns, nil))
+			continue
+		}
+		for _, defect := range test.Defects {
+			defectColumns := []string{string(defect.Type), strconv.FormatBool(defect.Dangerous), defect.Text}
+			rows = append(rows, row(vehicle, testColumns, defectColumns))
+		}
+	}
+	return rows
+}
+
+// row joins the vehicle, test and defect columns, padding missing ones with empty values
+func row(vehicle, test, defect []string) []string {
+	r := make([]string, 0, len(CSVHeader))
+	r = append(r, vehicle...)
+	r = append(r, test...)
+	r = append(r, defect...)
+	for len(r) < len(CSVHeader) {
+		r = append(r, "")
+	}
+	return r
+}
+
+// CSVText guards text from users against formula injection when the CSV is
+// opened in a spreadsheet, by prefixing values that start like a formula
+// with a single quote
+func CSVText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// csvDate formats a date for the CSV export, leaving missing dates empty
+func csvDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(csvDateLayout)
+}
+
+// SummaryHeader is the header row of the summary CSV
+var SummaryHeader = []string{
+	"input",
+	"registration",
+	"make",
+	"model",
+	"mot_result",
+	"mot_expiry",
+	"tax_status",
+	"tax_due",
+	"error",
+}
+
+// Summary is the MOT and tax status of a vehicle, one row of the summary CSV
+type Summary struct {
+	Input        string `json:"input"`
+	Registration string `json:"registration"`
+	Make         string `json:"make"`
+	Model        string `json:"model"`
+	MOTResult    string `json:"motResult"`
+	MOTExpiry    string `json:"motExpiry"`
+	TaxStatus    string `json:"taxStatus"`
+	TaxDue       string `json:"taxDue"`
+	Error        string `json:"error,omitempty"`
+}
+
+// NewSummary returns the status of a looked up vehicle. Input is the text
+// the registration was read from.
+func NewSummary(input string, result *lookup.Result) Summary {
+	s := Summary{Input: input, Registration: result.Registration}
+	if err := result.Err(); err != nil {
+		s.Error = err.Error()
+		return s
+	}
+
+	switch {
+	case result.MOT != nil:
+		s.Make = result.MOT.Make
+		s.Model = result.MOT.Model
+		if test := result.MOT.LatestTest(); test != nil {
+			s.MOTResult = test.TestResult
+		} else {
+			s.MOTResult = "NO TESTS"
+		}
+		if due, ok := result.MOT.MOTDueDate(); ok {
+			s.MOTExpiry = csvDate(due)
+		}
+	case result.NoMOTRecord():
+		s.MOTResult = "NO MOT RECORD"
+	default:
+		s.Error = result.MOTErr.Error()
+	}
+
+	if result.VES != nil {
+		if s.Make == "" {
+			s.Make = result.VES.Make
+		}
+		if s.MOTExpiry == "" {
+			s.MOTExpiry = csvDate(result.VES.MotExpiryDate.Time)
+		}
+		s.TaxStatus = result.VES.TaxStatus
+		s.TaxDue = csvDate(result.VES.TaxDueDate.Time)
+	} else if result.VESErr != nil {
+		s.Error = result.VESErr.Error()
+	}
+	return s
+}
+
+// SummaryCSV writes the summaries with one row per vehicle
+func SummaryCSV(w io.Writer, summaries []Summary) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(SummaryHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, s := range summaries {
+		record := []string{CSVText(s.Input), s.Registration, s.Make, s.Model, s.MOTResult, s.MOTExpiry, s.TaxStatus, s.TaxDue, s.Error}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/upstream"
+	"mot-bot/pkg/ves"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDate(t *testing.T, s string) mot.Date {
+	t.Helper()
+	d, err := mot.ParseDate(s)
+	require.NoError(t, err)
+	return d
+}
+
+func testResult(t *testing.T) *lookup.Result {
+	return &lookup.Result{
+		Registration: "AB12CDE",
+		MOT: &mot.VehicleResponse{
+			Registration:  "AB12CDE",
+			Make:          "FORD",
+			Model:         "FOCUS",
+			FuelType:      "Petrol",
+			PrimaryColour: "Blue",
+			FirstUsedDate: mustDate(t, "2012-03-01"),
+			MotTests: []mot.MotTest{
+				{
+					CompletedDate:      mustDate(t, "2024-03-01T10:00:00Z"),
+					TestResult:         "PASSED",
+					ExpiryDate:         mustDate(t, "2025-02-28"),
+					OdometerValue:      "80000",
+					OdometerUnit:       "MI",
+					OdometerResultType: mot.OdometerRead,
+					MotTestNumber:      "123456789012",
+				},
+				{
+					CompletedDate: mustDate(t, "2023-03-01T10:00:00Z"),
+					TestResult:    "FAILED",
+					MotTestNumber: "123456789011",
+					Defects: []mot.Defect{
+						{Text: "Brake pipe corroded, covered in grease, or other material", Type: mot.DefectMajor},
+						{Text: "Tyre slightly damaged", Type: mot.DefectAdvisory},
+					},
+				},
+			},
+		},
+		VES: &ves.Vehicle{
+			RegistrationNumber: "AB12CDE",
+			TaxStatus:          "Taxed",
+			TaxDueDate:         ves.CustomTime{Time: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, CSV(&buf, testResult(t), &lookup.Result{Registration: "XY99ZZZ", MOTErr: errors.New("not found")}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	assert.Equal(t, CSVHeader, records[0])
+
+	assert.Equal(t, []string{
+		"AB12CDE", "FORD", "FOCUS", "Petrol", "Blue", "2012-03-01", "Taxed", "2025-04-01", "2025-02-28",
+		"2024-03-01", "PASSED", "2025-02-28", "80000", "MI", "READ", "123456789012", "", "", "", "",
+	}, records[1])
+	assert.Equal(t, "2023-03-01", records[2][9])
+	assert.Equal(t, "MAJOR", records[2][17])
+	assert.Equal(t, "false", records[2][18])
+	assert.Equal(t, "Brake pipe corroded, covered in grease, or other material", records[2][19])
+	assert.Equal(t, "ADVISORY", records[3][17])
+
+	assert.Equal(t, "XY99ZZZ", records[4][0])
+	assert.Len(t, records[4], len(CSVHeader))
+}
+
+func TestJSON(t *testing.T) {
+	result := testResult(t)
+	result.VES = nil
+	result.VESErr = errors.New("VES API error: unavailable")
+
+	var buf bytes.Buffer
+	require.NoError(t, JSON(&buf, result, time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "AB12CDE", decoded["registration"])
+	assert.Equal(t, "2026-10-16T12:00:00Z", decoded["exportedAt"])
+	assert.Nil(t, decoded["ves"])
+	assert.Equal(t, "VES API error: unavailable", decoded["vesError"])
+
+	motData := decoded["mot"].(map[string]any)
+	assert.Equal(t, "FOCUS", motData["model"])
+	assert.Len(t, motData["motTests"], 2)
+}
+
+func TestSummaryCSV(t *testing.T) {
+	noMOT := &lookup.Result{
+		Registration: "NEW24CAR",
+		MOTErr:       fmt.Errorf("MOT API error: %w", upstream.ErrNotFound),
+		VES:          &ves.Vehicle{Make: "KIA", TaxStatus: "Taxed"},
+	}
+	failed := &lookup.Result{
+		Registration: "XY99ZZZ",
+		MOTErr:       errors.New("MOT API error: unavailable"),
+		VESErr:       errors.New("VES API error: unavailable"),
+	}
+	summaries := []Summary{
+		NewSummary("ab12 cde", testResult(t)),
+		NewSummary("NEW24CAR", noMOT),
+		NewSummary("XY99ZZZ", failed),
+		{Input: "hello", Error: "not a valid UK registration number"},
+		{Input: "=cmd|' /C calc'!A0", Error: "not a valid UK registration number"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, SummaryCSV(&buf, summaries))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, SummaryHeader, records[0])
+	assert.Equal(t, []string{"ab12 cde", "AB12CDE", "FORD", "FOCUS", "PASSED", "2025-02-28", "Taxed", "2025-04-01", ""}, records[1])
+	assert.Equal(t, []string{"NEW24CAR", "NEW24CAR", "KIA", "", "NO MOT RECORD", "", "Taxed", "", ""}, records[2])
+	assert.Contains(t, records[3][8], "VES API error: unavailable")
+	assert.Equal(t, "not a valid UK registration number", records[4][8])
+	assert.Equal(t, "'=cmd|' /C calc'!A0", records[5][0], "formulas are quoted")
+}
@@ -0,0 +1,82 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/upstream"
+	"mot-bot/pkg/ves"
+)
+
+// Result holds whatever the MOT and VES APIs returned for a registration.
+// Either side may be missing, in which case its error is set.
+type Result struct {
+	Registration string
+	MOT          *mot.VehicleResponse
+	VES          *ves.Vehicle
+	MOTErr       error
+	VESErr       error
+}
+
+// Fetch gets the vehicle data from both APIs concurrently and keeps the
+// successful responses even if the other API fails
+func Fetch(ctx context.Context, motClient mot.ClientInterface, vesClient ves.ClientInterface, registration string) *Result {
+	result := &Result{Registration: registration}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		vehicle, err := motClient.GetVehicleByRegistration(ctx, registration)
+		if err != nil {
+			result.MOTErr = fmt.Errorf("MOT API error: %w", err)
+			return
+		}
+		result.MOT = vehicle
+	}()
+
+	go func() {
+		defer wg.Done()
+		vehicle, err := vesClient.GetVehicleByRegistration(ctx, registration)
+		if err != nil {
+			result.VESErr = fmt.Errorf("VES API error: %w", err)
+			return
+		}
+		result.VES = vehicle
+	}()
+
+	wg.Wait()
+	return result
+}
+
+// Err returns an error if neither API returned any data. The VES error is
+// preferred because DVLA holds a record for every registered vehicle, while
+// vehicles too new for an MOT are legitimately missing from DVSA, unless only
+// the MOT error is transient, so the lookup is reported as worth retrying.
+func (r *Result) Err() error {
+	if r.MOT != nil || r.VES != nil {
+		return nil
+	}
+	if isTransient(r.MOTErr) && !isTransient(r.VESErr) {
+		return fmt.Errorf("%w (%v)", r.MOTErr, r.VESErr)
+	}
+	return fmt.Errorf("%w (%v)", r.VESErr, r.MOTErr)
+}
+
+// isTransient reports whether an error may go away when the lookup is retried
+func isTransient(err error) bool {
+	return errors.Is(err, upstream.ErrRateLimited) ||
+		errors.Is(err, upstream.ErrUnavailable) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled)
+}
+
+// NoMOTRecord reports whether DVSA has no record for the vehicle, which is
+// expected for vehicles that are too new to need an MOT
+func (r *Result) NoMOTRecord() bool {
+	return r.MOT == nil && errors.Is(r.MOTErr, upstream.ErrNotFound)
+}
@@ -0,0 +1,74 @@
+package lookup
+
+import (
+	"context"
+	"testing"
+
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/upstream"
+	"mot-bot/pkg/ves"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMOTClient struct {
+	vehicle *mot.VehicleResponse
+	err     error
+}
+
+func (c *fakeMOTClient) GetVehicleByRegistration(ctx context.Context, registration string) (*mot.VehicleResponse, error) {
+	return c.vehicle, c.err
+}
+
+type fakeVESClient struct {
+	vehicle *ves.Vehicle
+	err     error
+}
+
+func (c *fakeVESClient) GetVehicleByRegistration(ctx context.Context, registration string) (*ves.Vehicle, error) {
+	return c.vehicle, c.err
+}
+
+func TestFetch_NewVehicle(t *testing.T) {
+	motClient := &fakeMOTClient{err: upstream.NewError("MOT", 404, "MOTH-NP-01", "No MOT Tests found")}
+	vesClient := &fakeVESClient{vehicle: &ves.Vehicle{RegistrationNumber: "AB25CDE", TaxStatus: "Taxed"}}
+
+	result := Fetch(context.Background(), motClient, vesClient, "AB25CDE")
+
+	require.NoError(t, result.Err())
+	assert.True(t, result.NoMOTRecord())
+	assert.Nil(t, result.MOT)
+	assert.Equal(t, "Taxed", result.VES.TaxStatus)
+}
+
+func TestFetch_VESUnavailable(t *testing.T) {
+	motClient := &fakeMOTClient{vehicle: &mot.VehicleResponse{Registration: "AB12CDE"}}
+	vesClient := &fakeVESClient{err: upstream.NewError("VES", 503, "", "")}
+
+	result := Fetch(context.Background(), motClient, vesClient, "AB12CDE")
+
+	require.NoError(t, result.Err())
+	assert.False(t, result.NoMOTRecord())
+	assert.Equal(t, "AB12CDE", result.MOT.Registration)
+	assert.ErrorIs(t, result.VESErr, upstream.ErrUnavailable)
+}
+
+func TestFetch_BothFail(t *testing.T) {
+	motClient := &fakeMOTClient{err: upstream.NewError("MOT", 404, "", "")}
+	vesClient := &fakeVESClient{err: upstream.NewError("VES", 404, "", "")}
+
+	result := Fetch(context.Background(), motClient, vesClient, "AB12CDE")
+
+	assert.ErrorIs(t, result.Err(), upstream.ErrNotFound)
+}
+
+func TestFetch_BothFail_PrefersTransientError(t *testing.T) {
+	motClient := &fakeMOTClient{err: upstream.NewError("MOT", 429, "", "")}
+	vesClient := &fakeVESClient{err: upstream.NewError("VES", 404, "", "")}
+
+	result := Fetch(context.Background(), motClient, vesClient, "AB12CDE")
+
+	assert.ErrorIs(t, result.Err(), upstream.ErrRateLimited)
+	assert.NotErrorIs(t, result.Err(), upstream.ErrNotFound)
+}
@@ -0,0 +1,164 @@
+package mot
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// manualReferencePattern matches the MOT inspection manual references in a defect, such as "(5.2.3 (e))"
+var manualReferencePattern = regexp.MustCompile(`\(\d+(?:\.\d+)+[^()]*(?:\([^()]*\)[^()]*)*\)`)
+
+// defectStopWords are ignored when comparing the wording of defects
+var defectStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "to": true, "is": true, "are": true,
+	"and": true, "or": true, "in": true, "on": true, "at": true, "has": true, "have": true,
+	"with": true, "but": true, "not": true, "be": true, "by": true, "for": true, "as": true,
+	"slightly": true, "excessively": true, "likely": true,
+}
+
+// defectPositionWords describe where on the vehicle a defect is rather than what it is
+var defectPositionWords = map[string]bool{
+	"nearside": true, "offside": true, "front": true, "rear": true, "inner": true, "outer": true,
+	"left": true, "right": true, "centre": true, "center": true, "upper": true, "lower": true,
+}
+
+// KnownIssue is an advisory that was repeated without being fixed, or that later caused a failure
+type KnownIssue struct {
+	Text      string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	MOTs      int
+
+	// Outstanding is set if the advisory was still present at the latest MOT
+	Outstanding bool
+
+	// FailedOn is when a defect on the same part failed the vehicle, zero if it never did
+	FailedOn    time.Time
+	FailureText string
+}
+
+// advisoryHistory tracks a single advisory wording across MOTs
+type advisoryHistory struct {
+	issue        KnownIssue
+	subject      string
+	firstEpisode int
+	episodes     map[int]bool
+}
+
+// FindKnownIssues groups advisories across MOTs by their normalised wording and
+// returns those that were repeated and never fixed, or that later became a failure
+func FindKnownIssues(tests []MotTest) []KnownIssue {
+	episodes := motEpisodes(tests)
+	if len(episodes) == 0 {
+		return nil
+	}
+
+	histories := make(map[string]*advisoryHistory)
+	var order []string
+
+	for i, episode := range episodes {
+		for _, test := range episode {
+			date := test.CompletedDate.Time
+			for _, defect := range test.Defects {
+				if defect.IsFailure() {
+					markFailures(histories, i, date, defect)
+					continue
+				}
+				if defect.Severity() != DefectAdvisory {
+					continue
+				}
+
+				key := NormaliseDefectText(defect.Text)
+				if key == "" {
+					continue
+				}
+				h, ok := histories[key]
+				if !ok {
+					h = &advisoryHistory{
+						issue:        KnownIssue{FirstSeen: date},
+						subject:      defectSubject(defect.Text),
+						firstEpisode: i,
+						episodes:     make(map[int]bool),
+					}
+					histories[key] = h
+					order = append(order, key)
+				}
+				h.issue.Text = defect.Text
+				h.issue.LastSeen = date
+				h.episodes[i] = true
+			}
+		}
+	}
+
+	latest := len(episodes) - 1
+	var issues []KnownIssue
+	for _, key := range order {
+		h := histories[key]
+		h.issue.MOTs = len(h.episodes)
+		h.issue.Outstanding = h.episodes[latest]
+
+		repeated := h.issue.MOTs > 1 && h.issue.Outstanding
+		if repeated || !h.issue.FailedOn.IsZero() {
+			issues = append(issues, h.issue)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].FirstSeen.Before(issues[j].FirstSeen)
+	})
+	return issues
+}
+
+// markFailures records a failure against the advisories given at earlier MOTs on the same part
+func markFailures(histories map[string]*advisoryHistory, episode int, date time.Time, defect Defect) {
+	subject := defectSubject(defect.Text)
+	if subject == "" {
+		return
+	}
+	for _, h := range histories {
+		if h.subject != subject || h.firstEpisode >= episode || !h.issue.FailedOn.IsZero() {
+			continue
+		}
+		h.issue.FailedOn = date
+		h.issue.FailureText = defect.Text
+	}
+}
+
+// NormaliseDefectText reduces the wording of a defect to lower-case significant
+// words, so that the same advisory matches across MOTs
+func NormaliseDefectText(text string) string {
+	return strings.Join(defectWords(text), " ")
+}
+
+// defectWords returns the significant words of a defect, without manual references and stop words
+func defectWords(text string) []string {
+	text = manualReferencePattern.ReplaceAllString(strings.ToLower(text), " ")
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, w := range fields {
+		if !defectStopWords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// defectSubject returns the position and the part a defect is about, such as
+// "nearside front tyre", so that an advisory can be matched with a later failure
+func defectSubject(text string) string {
+	var position []string
+	for _, w := range defectWords(text) {
+		if defectPositionWords[w] {
+			position = append(position, w)
+			continue
+		}
+		return strings.Join(append(position, w), " ")
+	}
+	return ""
+}
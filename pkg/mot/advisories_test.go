@@ -0,0 +1,56 @@
+package mot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormaliseDefectText(t *testing.T) {
+	assert.Equal(t, "nearside front tyre worn close legal limit",
+		NormaliseDefectText("Nearside Front Tyre worn close to the legal limit (5.2.3 (e))"))
+	assert.Equal(t, NormaliseDefectText("Nearside front tyre worn close to legal limit"),
+		NormaliseDefectText("Nearside Front Tyre worn close to legal limit (5.2.3 (e))"))
+	assert.Equal(t, "nearside front tyre", defectSubject("Nearside Front Tyre tread depth below requirements of 1.6mm (5.2.3 (e))"))
+}
+
+func TestFindKnownIssues(t *testing.T) {
+	tests := []MotTest{
+		{CompletedDate: testDate("2019-04-01T10:00:00Z"), TestResult: "PASSED", Defects: []Defect{
+			{Text: "Nearside front tyre worn close to legal limit", Type: "ADVISORY"},
+			{Text: "Oil leak", Type: "ADVISORY"},
+			{Text: "Offside rear brake pipe slightly corroded (1.1.11 (c))", Type: "ADVISORY"},
+		}},
+		{CompletedDate: testDate("2020-04-01T10:00:00Z"), TestResult: "PASSED", Defects: []Defect{
+			{Text: "Nearside Front Tyre worn close to the legal limit (5.2.3 (e))", Type: "ADVISORY"},
+			{Text: "Offside rear brake pipe corroded (1.1.11 (c))", Type: "ADVISORY"},
+		}},
+		{CompletedDate: testDate("2021-04-01T10:00:00Z"), TestResult: "FAILED", Defects: []Defect{
+			{Text: "Nearside Front Tyre tread depth below requirements of 1.6mm (5.2.3 (e))", Type: "MAJOR"},
+			{Text: "Offside rear brake pipe corroded (1.1.11 (c))", Type: "ADVISORY"},
+		}},
+		{CompletedDate: testDate("2021-04-03T10:00:00Z"), TestResult: "PASSED", Defects: []Defect{
+			{Text: "Offside rear brake pipe corroded (1.1.11 (c))", Type: "ADVISORY"},
+		}},
+	}
+
+	issues := FindKnownIssues(tests)
+
+	require.Len(t, issues, 2)
+
+	assert.Equal(t, "Nearside Front Tyre worn close to the legal limit (5.2.3 (e))", issues[0].Text)
+	assert.Equal(t, 2, issues[0].MOTs)
+	assert.False(t, issues[0].Outstanding)
+	assert.Equal(t, time.Date(2021, 4, 1, 10, 0, 0, 0, time.UTC), issues[0].FailedOn)
+
+	assert.Equal(t, "Offside rear brake pipe corroded (1.1.11 (c))", issues[1].Text)
+	assert.Equal(t, 3, issues[1].MOTs)
+	assert.True(t, issues[1].Outstanding)
+	assert.True(t, issues[1].FailedOn.IsZero())
+}
+
+func TestFindKnownIssues_NoTests(t *testing.T) {
+	assert.Empty(t, FindKnownIssues(nil))
+}
@@ -4,19 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mot-bot/pkg/upstream"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 )
 
 const (
+	// BaseURL is the vehicles endpoint of the DVSA MOT history API
+	BaseURL = "https://history.mot.api.gov.uk/v1/trade/vehicles"
+
 	scopeURL = "https://tapi.dvsa.gov.uk/.default"
 )
 
-var (
-	srcBaseURL = "https://history.mot.api.gov.uk/v1/trade/vehicles"
-	tokenURL   = getTokenURL()
-)
+var tokenURL = getTokenURL()
 
 // getTokenURL returns the token URL from environment variables or a default value
 func getTokenURL() string {
@@ -46,34 +49,69 @@ func NewClient(httpClient *http.Client, apiKey, baseURL string) *Client {
 	}
 }
 
+// Recall statuses reported in VehicleResponse.HasOutstandingRecall
+const (
+	RecallYes         = "Yes"
+	RecallNo          = "No"
+	RecallUnknown     = "Unknown"
+	RecallUnavailable = "Unavailable"
+)
+
+// Odometer result types of a MOT test
+const (
+	OdometerRead       = "READ"
+	OdometerUnreadable = "UNREADABLE"
+	OdometerNone       = "NO_ODOMETER"
+)
+
+// Data sources of a MOT test
+const (
+	DataSourceDVSA = "DVSA"
+	DataSourceDVA  = "DVA NI"
+	DataSourceCVS  = "CVS"
+)
+
+// VehicleResponse is a vehicle returned by the DVSA MOT history API. Vehicles
+// too new to have had an MOT have no tests but a MotTestDueDate.
 type VehicleResponse struct {
-	Registration     string    `json:"registration"`
-	Make             string    `json:"make"`
-	Model            string    `json:"model"`
-	FirstUsedDate    string    `json:"firstUsedDate"`
-	FuelType         string    `json:"fuelType"`
-	PrimaryColour    string    `json:"primaryColour"`
-	RegistrationDate string    `json:"registrationDate"`
-	ManufactureDate  string    `json:"manufactureDate"`
-	EngineSize       string    `json:"engineSize"`
-	MotTests         []MotTest `json:"motTests"`
+	Registration         string    `json:"registration"`
+	Make                 string    `json:"make"`
+	Model                string    `json:"model"`
+	FirstUsedDate        Date      `json:"firstUsedDate"`
+	FuelType             string    `json:"fuelType"`
+	PrimaryColour        string    `json:"primaryColour"`
+	RegistrationDate     Date      `json:"registrationDate"`
+	ManufactureDate      Date      `json:"manufactureDate"`
+	ManufactureYear      string    `json:"manufactureYear,omitempty"`
+	EngineSize           string    `json:"engineSize"`
+	HasOutstandingRecall string    `json:"hasOutstandingRecall"`
+	MotTestDueDate       Date      `json:"motTestDueDate"`
+	DvlaID               string    `json:"dvlaId,omitempty"`
+	MotTests             []MotTest `json:"motTests"`
+
+	// FetchedAt is when the data was retrieved from the API
+	FetchedAt time.Time `json:"-"`
 }
 
+// MotTest is a single MOT test of a vehicle
 type MotTest struct {
-	CompletedDate      string   `json:"completedDate"`
-	TestResult         string   `json:"testResult"`
-	ExpiryDate         string   `json:"expiryDate"`
-	OdometerValue      string   `json:"odometerValue"`
-	OdometerUnit       string   `json:"odometerUnit"`
-	OdometerResultType string   `json:"odometerResultType"`
-	MotTestNumber      string   `json:"motTestNumber"`
-	Defects            []Defect `json:"defects"`
+	CompletedDate            Date     `json:"completedDate"`
+	TestResult               string   `json:"testResult"`
+	ExpiryDate               Date     `json:"expiryDate"`
+	OdometerValue            string   `json:"odometerValue"`
+	OdometerUnit             string   `json:"odometerUnit"`
+	OdometerResultType       string   `json:"odometerResultType"`
+	MotTestNumber            string   `json:"motTestNumber"`
+	DataSource               string   `json:"dataSource"`
+	Location                 string   `json:"location,omitempty"`
+	RegistrationAtTimeOfTest string   `json:"registrationAtTimeOfTest,omitempty"`
+	Defects                  []Defect `json:"defects"`
 }
 
 type Defect struct {
-	Text      string `json:"text"`
-	Type      string `json:"type"`
-	Dangerous bool   `json:"dangerous"`
+	Text      string     `json:"text"`
+	Type      DefectType `json:"type"`
+	Dangerous bool       `json:"dangerous"`
 }
 
 func (c *Client) GetVehicleByRegistration(ctx context.Context, registration string) (*VehicleResponse, error) {
@@ -88,22 +126,92 @@ func (c *Client) GetVehicleByRegistration(ctx context.Context, registration stri
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
+		// A timeout or cancellation of the caller is not a fault of the service
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to make request: %w: %w", upstream.ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, decodeError(resp)
 	}
 
 	var vehicle VehicleResponse
 	if err := json.NewDecoder(resp.Body).Decode(&vehicle); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	vehicle.FetchedAt = time.Now()
 
 	return &vehicle, nil
 }
 
+// errorResponse is the error body returned by the DVSA API
+type errorResponse struct {
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+	RequestID    string `json:"requestId"`
+}
+
+// decodeError converts an unsuccessful response into an *upstream.Error
+func decodeError(resp *http.Response) error {
+	var body errorResponse
+	// The body is optional, so a missing or malformed one is not an error
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+	return upstream.NewError("MOT", resp.StatusCode, body.ErrorCode, body.ErrorMessage)
+}
+
+// LatestTest returns the most recently completed MOT test, or nil if the vehicle has none
+func (v *VehicleResponse) LatestTest() *MotTest {
+	var latest *MotTest
+	for i := range v.MotTests {
+		if latest == nil || v.MotTests[i].CompletedDate.After(latest.CompletedDate.Time) {
+			latest = &v.MotTests[i]
+		}
+	}
+	return latest
+}
+
+// LatestExpiryDate returns the latest expiry date across all MOT tests
+func (v *VehicleResponse) LatestExpiryDate() (time.Time, bool) {
+	var latest time.Time
+	for _, test := range v.MotTests {
+		if test.ExpiryDate.After(latest) {
+			latest = test.ExpiryDate.Time
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+// MOTDueDate returns when the next MOT is due: the latest expiry date, or
+// for a vehicle that has never had an MOT, the date the first one is due
+func (v *VehicleResponse) MOTDueDate() (time.Time, bool) {
+	if expiry, ok := v.LatestExpiryDate(); ok {
+		return expiry, true
+	}
+	return v.MotTestDueDate.Time, !v.MotTestDueDate.IsZero()
+}
+
+// OutstandingRecall reports whether the vehicle has a safety recall that hasn't been fixed
+func (v *VehicleResponse) OutstandingRecall() bool {
+	return strings.EqualFold(v.HasOutstandingRecall, RecallYes)
+}
+
+// OdometerResultDescription explains the odometer result type of a test
+func (t *MotTest) OdometerResultDescription() string {
+	switch strings.ToUpper(t.OdometerResultType) {
+	case OdometerRead, "":
+		return "Odometer read"
+	case OdometerUnreadable:
+		return "Odometer present but unreadable"
+	case OdometerNone:
+		return "Vehicle has no odometer"
+	default:
+		return t.OdometerResultType
+	}
+}
+
 // FormatVehicleInfo returns a formatted string representation of the vehicle information
 func (v *VehicleResponse) FormatVehicleInfo() string {
 	var result strings.Builder
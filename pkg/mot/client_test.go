@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"mot-bot/pkg/upstream"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGetVehicleByRegistration(t *testing.T) {
@@ -37,17 +39,17 @@ func TestGetVehicleByRegistration(t *testing.T) {
 			Registration:     "AB12CDE",
 			Make:             "FORD",
 			Model:            "FOCUS",
-			FirstUsedDate:    "2010-01-01",
+			FirstUsedDate:    testDate("2010-01-01"),
 			FuelType:         "PETROL",
 			PrimaryColour:    "BLUE",
-			RegistrationDate: "2010-01-01",
-			ManufactureDate:  "2009-12-01",
+			RegistrationDate: testDate("2010-01-01"),
+			ManufactureDate:  testDate("2009-12-01"),
 			EngineSize:       "1596",
 			MotTests: []MotTest{
 				{
-					CompletedDate:      "2023-01-01T00:00:00Z",
+					CompletedDate:      testDate("2023-01-01T00:00:00Z"),
 					TestResult:         "PASSED",
-					ExpiryDate:         "2024-01-01",
+					ExpiryDate:         testDate("2024-01-01"),
 					OdometerValue:      "100000",
 					OdometerUnit:       "MI",
 					OdometerResultType: "READ",
@@ -72,7 +74,7 @@ func TestGetVehicleByRegistration(t *testing.T) {
 	// Override the URLs for testing
 	tokenURL = tokenServer.URL
 
-	httpClient := CreateHTTPClient("test-client-id", "test-client-secret", tokenServer.URL)
+	httpClient := CreateHTTPClient("test-client-id", "test-client-secret", tokenServer.URL, upstream.Policy{})
 
 	// Create a client
 	client := NewClient(httpClient, "test-api-key", apiServer.URL)
@@ -102,3 +104,47 @@ func TestGetVehicleByRegistration_Error(t *testing.T) {
 	_, err := client.GetVehicleByRegistration(context.Background(), "AB12CDE")
 	assert.Error(t, err)
 }
+
+func TestGetVehicleByRegistration_ErrorBody(t *testing.T) {
+	// Create a test server for the MOT API that returns a DVSA error body
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		err := json.NewEncoder(w).Encode(map[string]string{
+			"errorCode":    "MOTH-NP-01",
+			"errorMessage": "No MOT Tests found with vehicle registration : AB12CDE",
+			"requestId":    "test-request",
+		})
+		require.NoError(t, err)
+	}))
+	defer apiServer.Close()
+
+	// Create a client
+	client := NewClient(&http.Client{}, "test-api-key", apiServer.URL)
+
+	// Test the function
+	_, err := client.GetVehicleByRegistration(context.Background(), "AB12CDE")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, upstream.ErrNotFound)
+
+	var apiErr *upstream.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "MOTH-NP-01", apiErr.Code)
+	assert.Equal(t, "No MOT Tests found with vehicle registration : AB12CDE", apiErr.Message)
+}
+
+func TestGetVehicleByRegistration_ContextError(t *testing.T) {
+	// Create a test server that answers slower than the caller waits
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer apiServer.Close()
+
+	client := NewClient(&http.Client{}, "test-api-key", apiServer.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetVehicleByRegistration(ctx, "AB12CDE")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, upstream.ErrUnavailable)
+}
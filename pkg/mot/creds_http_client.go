@@ -3,11 +3,12 @@ package mot
 import (
 	"context"
 	"golang.org/x/oauth2/clientcredentials"
+	"mot-bot/pkg/upstream"
 	"net/http"
 	"time"
 )
 
-func CreateHTTPClient(clientID, clientSecret, tokenURL string) *http.Client {
+func CreateHTTPClient(clientID, clientSecret, tokenURL string, policy upstream.Policy) *http.Client {
 	config := &clientcredentials.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
@@ -19,5 +20,8 @@ func CreateHTTPClient(clientID, clientSecret, tokenURL string) *http.Client {
 	httpClient := config.Client(context.Background())
 	httpClient.Timeout = 30 * time.Second
 
+	// Retry and rate limit the API requests, the token is attached on every attempt
+	httpClient.Transport = upstream.NewTransport(httpClient.Transport, policy)
+
 	return httpClient
 }
@@ -0,0 +1,76 @@
+package mot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the date and timestamp formats the DVSA API has used
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006.01.02 15:04:05",
+	"2006.01.02",
+}
+
+// Date is a date or timestamp returned by the DVSA API. The zero value means
+// the date is not known.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a DVSA date such as "2024-01-31" or a timestamp such as "2024-01-31T09:30:00.000Z"
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Date{t}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("invalid date %q", s)
+}
+
+// UnmarshalJSON parses a DVSA date, treating null and empty strings as an unknown date
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalJSON writes the date as RFC 3339, or null if it is not known
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.Format(time.RFC3339Nano))
+}
+
+// String returns the date as DD.MM.YYYY, or an empty string if it is not known
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format("02.01.2006")
+}
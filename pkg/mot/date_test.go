@@ -0,0 +1,66 @@
+package mot
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDate parses a date for test fixtures, panicking if it is invalid
+func testDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestParseDate(t *testing.T) {
+	cases := map[string]time.Time{
+		"2024-01-31":               time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		"2024.01.31":               time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		"2024-01-31T09:30:00.000Z": time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC),
+		"2024-01-31 09:30:00":      time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC),
+		"2024.01.31 09:30:00":      time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC),
+	}
+	for s, want := range cases {
+		d, err := ParseDate(s)
+		require.NoError(t, err, s)
+		assert.True(t, want.Equal(d.Time), s)
+	}
+
+	_, err := ParseDate("31/01/2024")
+	assert.Error(t, err)
+}
+
+func TestDate_JSON(t *testing.T) {
+	var vehicle VehicleResponse
+	err := json.Unmarshal([]byte(`{
+		"registration": "AB23CDE",
+		"firstUsedDate": null,
+		"registrationDate": "",
+		"manufactureDate": "2023-03-01",
+		"motTestDueDate": "2026-02-28",
+		"hasOutstandingRecall": "Yes"
+	}`), &vehicle)
+	require.NoError(t, err)
+
+	assert.True(t, vehicle.FirstUsedDate.IsZero())
+	assert.True(t, vehicle.RegistrationDate.IsZero())
+	assert.Equal(t, "01.03.2023", vehicle.ManufactureDate.String())
+	assert.True(t, vehicle.OutstandingRecall())
+
+	due, ok := vehicle.MOTDueDate()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), due)
+
+	data, err := json.Marshal(vehicle)
+	require.NoError(t, err)
+
+	var decoded VehicleResponse
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, vehicle, decoded)
+}
@@ -0,0 +1,109 @@
+package mot
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefectType is the category DVSA gives a defect
+type DefectType string
+
+// Defect types used by DVSA. FAIL is only found on tests from before the
+// May 2018 defect categories were introduced.
+const (
+	DefectDangerous   DefectType = "DANGEROUS"
+	DefectMajor       DefectType = "MAJOR"
+	DefectFail        DefectType = "FAIL"
+	DefectPRS         DefectType = "PRS"
+	DefectMinor       DefectType = "MINOR"
+	DefectAdvisory    DefectType = "ADVISORY"
+	DefectNonSpecific DefectType = "NON SPECIFIC"
+	DefectUserEntered DefectType = "USER ENTERED"
+)
+
+// defectTypeOrder is the order defects are grouped in, most severe first
+var defectTypeOrder = []DefectType{
+	DefectDangerous,
+	DefectMajor,
+	DefectFail,
+	DefectPRS,
+	DefectMinor,
+	DefectAdvisory,
+	DefectNonSpecific,
+	DefectUserEntered,
+}
+
+// Normalise returns the type in the upper case form DVSA uses
+func (t DefectType) Normalise() DefectType {
+	return DefectType(strings.ToUpper(strings.TrimSpace(string(t))))
+}
+
+// IsFailure reports whether a defect of this type fails the MOT. PRS defects
+// failed the test but were repaired within an hour of it.
+func (t DefectType) IsFailure() bool {
+	switch t.Normalise() {
+	case DefectDangerous, DefectMajor, DefectFail, DefectPRS:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rank returns the position of the type in the grouping order. Unknown types come last.
+func (t DefectType) Rank() int {
+	t = t.Normalise()
+	for i, known := range defectTypeOrder {
+		if t == known {
+			return i
+		}
+	}
+	return len(defectTypeOrder)
+}
+
+// Label returns a human-readable name for the type
+func (t DefectType) Label() string {
+	switch t.Normalise() {
+	case DefectDangerous:
+		return "Dangerous"
+	case DefectMajor:
+		return "Major"
+	case DefectFail:
+		return "Fail"
+	case DefectPRS:
+		return "PRS"
+	case DefectMinor:
+		return "Minor"
+	case DefectAdvisory:
+		return "Advisory"
+	case DefectNonSpecific:
+		return "Non-specific"
+	case DefectUserEntered:
+		return "User entered"
+	default:
+		return string(t)
+	}
+}
+
+// Severity returns the type of the defect, treating any defect flagged as dangerous as DANGEROUS
+func (d Defect) Severity() DefectType {
+	if d.Dangerous {
+		return DefectDangerous
+	}
+	return d.Type.Normalise()
+}
+
+// IsFailure reports whether the defect fails the MOT
+func (d Defect) IsFailure() bool {
+	return d.Severity().IsFailure()
+}
+
+// SortDefects returns a copy of the defects grouped by severity, keeping the
+// DVSA order within each group
+func SortDefects(defects []Defect) []Defect {
+	sorted := make([]Defect, len(defects))
+	copy(sorted, defects)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Severity().Rank() < sorted[j].Severity().Rank()
+	})
+	return sorted
+}
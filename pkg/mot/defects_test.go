@@ -0,0 +1,46 @@
+package mot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefectType_IsFailure(t *testing.T) {
+	failures := []DefectType{DefectDangerous, DefectMajor, DefectFail, DefectPRS, "major"}
+	for _, typ := range failures {
+		assert.True(t, typ.IsFailure(), typ)
+	}
+
+	others := []DefectType{DefectMinor, DefectAdvisory, DefectNonSpecific, DefectUserEntered, "SYSTEM GENERATED"}
+	for _, typ := range others {
+		assert.False(t, typ.IsFailure(), typ)
+	}
+}
+
+func TestDefect_Severity(t *testing.T) {
+	assert.Equal(t, DefectDangerous, Defect{Type: DefectMajor, Dangerous: true}.Severity())
+	assert.Equal(t, DefectMinor, Defect{Type: "minor"}.Severity())
+	assert.True(t, Defect{Type: DefectMinor, Dangerous: true}.IsFailure())
+}
+
+func TestSortDefects(t *testing.T) {
+	defects := []Defect{
+		{Text: "a", Type: DefectAdvisory},
+		{Text: "b", Type: "SYSTEM GENERATED"},
+		{Text: "c", Type: DefectMinor},
+		{Text: "d", Type: DefectMajor, Dangerous: true},
+		{Text: "e", Type: DefectPRS},
+		{Text: "f", Type: DefectMajor},
+		{Text: "g", Type: DefectUserEntered},
+		{Text: "h", Type: DefectAdvisory},
+		{Text: "i", Type: DefectNonSpecific},
+	}
+
+	var order []string
+	for _, d := range SortDefects(defects) {
+		order = append(order, d.Text)
+	}
+	assert.Equal(t, []string{"d", "f", "e", "c", "a", "h", "i", "g", "b"}, order)
+	assert.Equal(t, "a", defects[0].Text)
+}
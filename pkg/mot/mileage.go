@@ -0,0 +1,186 @@
+package mot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mileage anomaly kinds
+const (
+	AnomalyRollback   = "rollback"
+	AnomalyUnitChange = "unit_change"
+	AnomalyJump       = "jump"
+	AnomalyGap        = "gap"
+)
+
+const (
+	kmToMiles = 0.621371
+
+	// maxPlausibleMilesPerYear is the annual mileage above which a jump between readings is flagged
+	maxPlausibleMilesPerYear = 40000
+
+	// minJumpPeriod stops readings taken a few days apart from producing huge yearly rates
+	minJumpPeriod = 90 * 24 * time.Hour
+
+	// maxTestGap is the time between tests after which the history is flagged as incomplete
+	maxTestGap = 2 * 365 * 24 * time.Hour
+)
+
+// MileageReading is a single odometer reading converted to miles
+type MileageReading struct {
+	Date   time.Time
+	Miles  int
+	Value  string
+	Unit   string
+	Failed bool
+}
+
+// MileageAnomaly is an irregularity found in the odometer history
+type MileageAnomaly struct {
+	Kind        string
+	Date        time.Time
+	Description string
+}
+
+// MileageReport is the result of analysing the odometer readings of a vehicle
+type MileageReport struct {
+	Readings   []MileageReading
+	Anomalies  []MileageAnomaly
+	Unreadable int
+}
+
+// AnalyseMileage checks the odometer readings of the given MOT tests for
+// readings that go backwards, unit switches, implausible jumps and long gaps
+func AnalyseMileage(tests []MotTest) MileageReport {
+	sorted := make([]MotTest, len(tests))
+	copy(sorted, tests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CompletedDate.Before(sorted[j].CompletedDate.Time)
+	})
+
+	var report MileageReport
+	var prevTestDate time.Time
+
+	for _, test := range sorted {
+		date := test.CompletedDate.Time
+		if date.IsZero() {
+			continue
+		}
+
+		if !prevTestDate.IsZero() && date.Sub(prevTestDate) > maxTestGap {
+			report.Anomalies = append(report.Anomalies, MileageAnomaly{
+				Kind:        AnomalyGap,
+				Date:        date,
+				Description: fmt.Sprintf("No MOT test for %.1f years before this one", date.Sub(prevTestDate).Hours()/24/365),
+			})
+		}
+		prevTestDate = date
+
+		reading, ok := parseReading(test, date)
+		if !ok {
+			report.Unreadable++
+			continue
+		}
+
+		if n := len(report.Readings); n > 0 {
+			prev := report.Readings[n-1]
+			report.Anomalies = append(report.Anomalies, compareReadings(prev, reading)...)
+		}
+		report.Readings = append(report.Readings, reading)
+	}
+
+	return report
+}
+
+// compareReadings returns the anomalies between two consecutive readings. A
+// change of odometer unit is reported on its own, because the converted
+// readings are too imprecise to judge a rollback or jump across it.
+func compareReadings(prev, cur MileageReading) []MileageAnomaly {
+	var anomalies []MileageAnomaly
+
+	if prev.Unit != cur.Unit {
+		return append(anomalies, MileageAnomaly{
+			Kind:        AnomalyUnitChange,
+			Date:        cur.Date,
+			Description: fmt.Sprintf("Odometer unit changed from %s to %s", prev.Unit, cur.Unit),
+		})
+	}
+
+	delta := cur.Miles - prev.Miles
+	if delta < 0 {
+		anomalies = append(anomalies, MileageAnomaly{
+			Kind:        AnomalyRollback,
+			Date:        cur.Date,
+			Description: fmt.Sprintf("Mileage went backwards from %d to %d miles", prev.Miles, cur.Miles),
+		})
+		return anomalies
+	}
+
+	period := cur.Date.Sub(prev.Date)
+	if period < minJumpPeriod {
+		period = minJumpPeriod
+	}
+	perYear := float64(delta) / (period.Hours() / 24 / 365)
+	if perYear > maxPlausibleMilesPerYear {
+		anomalies = append(anomalies, MileageAnomaly{
+			Kind:        AnomalyJump,
+			Date:        cur.Date,
+			Description: fmt.Sprintf("Mileage jumped by %d miles (about %.0f miles per year)", delta, perYear),
+		})
+	}
+
+	return anomalies
+}
+
+// parseReading returns the odometer reading of a test if it was read successfully
+func parseReading(test MotTest, date time.Time) (MileageReading, bool) {
+	if test.OdometerResultType != "" && !strings.EqualFold(test.OdometerResultType, OdometerRead) {
+		return MileageReading{}, false
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(test.OdometerValue))
+	if err != nil {
+		return MileageReading{}, false
+	}
+
+	unit := strings.ToUpper(test.OdometerUnit)
+	miles := value
+	if unit == "KM" {
+		miles = int(float64(value) * kmToMiles)
+	}
+
+	return MileageReading{
+		Date:   date,
+		Miles:  miles,
+		Value:  test.OdometerValue,
+		Unit:   unit,
+		Failed: strings.EqualFold(test.TestResult, "FAILED"),
+	}, true
+}
+
+// HasRollback reports whether any reading went backwards
+func (r *MileageReport) HasRollback() bool {
+	for _, a := range r.Anomalies {
+		if a.Kind == AnomalyRollback {
+			return true
+		}
+	}
+	return false
+}
+
+// Verdict returns a plain summary of the mileage check
+func (r *MileageReport) Verdict() string {
+	switch {
+	case len(r.Readings) == 0:
+		return "No odometer readings to check"
+	case r.HasRollback():
+		return "Possible clocking: the mileage went backwards"
+	case len(r.Anomalies) > 0:
+		return "Irregular mileage history, check the details"
+	default:
+		return "Mileage history looks consistent"
+	}
+}
@@ -0,0 +1,70 @@
+package mot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyseMileage_Consistent(t *testing.T) {
+	tests := []MotTest{
+		{CompletedDate: testDate("2023-01-10T10:00:00Z"), TestResult: "PASSED", OdometerValue: "30000", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2021-01-10T10:00:00Z"), TestResult: "PASSED", OdometerValue: "10000", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2022-01-10T10:00:00Z"), TestResult: "FAILED", OdometerValue: "20000", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2022-01-12T10:00:00Z"), TestResult: "PASSED", OdometerValue: "20010", OdometerUnit: "MI", OdometerResultType: "READ"},
+	}
+
+	report := AnalyseMileage(tests)
+
+	require.Len(t, report.Readings, 4)
+	assert.Equal(t, 10000, report.Readings[0].Miles)
+	assert.True(t, report.Readings[1].Failed)
+	assert.Empty(t, report.Anomalies)
+	assert.Equal(t, "Mileage history looks consistent", report.Verdict())
+}
+
+func TestAnalyseMileage_Anomalies(t *testing.T) {
+	tests := []MotTest{
+		{CompletedDate: testDate("2015-03-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "50000", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2016-03-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "100000", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2017-03-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "60000", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2018-03-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "", OdometerUnit: "", OdometerResultType: "UNREADABLE"},
+		{CompletedDate: testDate("2021-03-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "110000", OdometerUnit: "KM", OdometerResultType: "READ"},
+	}
+
+	report := AnalyseMileage(tests)
+
+	assert.Len(t, report.Readings, 4)
+	assert.Equal(t, 1, report.Unreadable)
+	assert.Equal(t, 68350, report.Readings[3].Miles)
+
+	var kinds []string
+	for _, a := range report.Anomalies {
+		kinds = append(kinds, a.Kind)
+	}
+	assert.Equal(t, []string{AnomalyJump, AnomalyRollback, AnomalyGap, AnomalyUnitChange}, kinds)
+	assert.True(t, report.HasRollback())
+	assert.Equal(t, "Possible clocking: the mileage went backwards", report.Verdict())
+}
+
+func TestAnalyseMileage_UnitChangeOnly(t *testing.T) {
+	// A miles odometer replaced by a kilometres one reads lower after conversion
+	tests := []MotTest{
+		{CompletedDate: testDate("2020-03-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "90000", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2021-03-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "20000", OdometerUnit: "KM", OdometerResultType: "READ"},
+	}
+
+	report := AnalyseMileage(tests)
+
+	require.Len(t, report.Anomalies, 1)
+	assert.Equal(t, AnomalyUnitChange, report.Anomalies[0].Kind)
+	assert.False(t, report.HasRollback())
+}
+
+func TestAnalyseMileage_NoReadings(t *testing.T) {
+	report := AnalyseMileage(nil)
+
+	assert.Empty(t, report.Readings)
+	assert.Equal(t, "No odometer readings to check", report.Verdict())
+}
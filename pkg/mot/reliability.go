@@ -0,0 +1,174 @@
+package mot
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// retestWindow is how soon after a failure a test is treated as a retest rather than a new annual MOT
+	retestWindow = 60 * 24 * time.Hour
+
+	// minRecurrence is the number of separate MOTs an area must appear in to be recurring
+	minRecurrence = 2
+
+	yearDuration = 365.25 * 24 * time.Hour
+)
+
+// RecurringArea is a vehicle system that had defects at several MOTs
+type RecurringArea struct {
+	Area string
+	MOTs int
+}
+
+// Reliability holds metrics derived from the MOT history of a vehicle
+type Reliability struct {
+	Tests           int
+	Failures        int
+	FirstAttempts   int
+	FirstTimePasses int
+	Years           float64
+	Recurring       []RecurringArea
+
+	// MilesPerYear is the average annual mileage, zero if it can't be worked out
+	MilesPerYear int
+}
+
+// AnalyseReliability works out the first-time pass rate, failures per year,
+// recurring defect areas and average annual mileage from the MOT tests
+func AnalyseReliability(tests []MotTest) Reliability {
+	var r Reliability
+	var first, last time.Time
+	areaEpisodes := make(map[string]map[int]bool)
+
+	episodes := motEpisodes(tests)
+	for i, episode := range episodes {
+		r.FirstAttempts++
+		if !strings.EqualFold(episode[0].TestResult, "FAILED") {
+			r.FirstTimePasses++
+		}
+
+		for _, test := range episode {
+			date := test.CompletedDate.Time
+			if first.IsZero() {
+				first = date
+			}
+			last = date
+
+			r.Tests++
+			if strings.EqualFold(test.TestResult, "FAILED") {
+				r.Failures++
+			}
+
+			for _, defect := range test.Defects {
+				area, ok := defectArea(defect)
+				if !ok {
+					continue
+				}
+				if areaEpisodes[area] == nil {
+					areaEpisodes[area] = make(map[int]bool)
+				}
+				areaEpisodes[area][i] = true
+			}
+		}
+	}
+
+	if r.Tests == 0 {
+		return r
+	}
+
+	// Each MOT covers a year, so the history spans a year beyond the last test
+	r.Years = last.Sub(first).Hours()/yearDuration.Hours() + 1
+
+	for area, episodes := range areaEpisodes {
+		if len(episodes) >= minRecurrence {
+			r.Recurring = append(r.Recurring, RecurringArea{Area: area, MOTs: len(episodes)})
+		}
+	}
+	sort.Slice(r.Recurring, func(i, j int) bool {
+		if r.Recurring[i].MOTs != r.Recurring[j].MOTs {
+			return r.Recurring[i].MOTs > r.Recurring[j].MOTs
+		}
+		return r.Recurring[i].Area < r.Recurring[j].Area
+	})
+
+	mileage := AnalyseMileage(tests)
+	if n := len(mileage.Readings); n >= 2 {
+		firstReading, lastReading := mileage.Readings[0], mileage.Readings[n-1]
+		period := lastReading.Date.Sub(firstReading.Date)
+		if period >= minJumpPeriod && lastReading.Miles > firstReading.Miles {
+			r.MilesPerYear = int(float64(lastReading.Miles-firstReading.Miles) / (period.Hours() / yearDuration.Hours()))
+		}
+	}
+
+	return r
+}
+
+// motEpisodes groups tests in chronological order into MOTs, treating a test
+// shortly after a failure as a retest of the same MOT
+func motEpisodes(tests []MotTest) [][]MotTest {
+	sorted := make([]MotTest, 0, len(tests))
+	for _, test := range tests {
+		if !test.CompletedDate.IsZero() {
+			sorted = append(sorted, test)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CompletedDate.Before(sorted[j].CompletedDate.Time)
+	})
+
+	var episodes [][]MotTest
+	var prevDate time.Time
+	prevFailed := false
+	for _, test := range sorted {
+		date := test.CompletedDate.Time
+		if len(episodes) == 0 || !prevFailed || date.Sub(prevDate) > retestWindow {
+			episodes = append(episodes, nil)
+		}
+		episodes[len(episodes)-1] = append(episodes[len(episodes)-1], test)
+		prevDate, prevFailed = date, strings.EqualFold(test.TestResult, "FAILED")
+	}
+	return episodes
+}
+
+// defectArea returns the vehicle system a defect belongs to, ignoring
+// defects that can't be classified
+func defectArea(defect Defect) (string, bool) {
+	if defect.Severity() == DefectUserEntered {
+		return "", false
+	}
+	system := defect.System()
+	return system, system != SystemOther
+}
+
+// FirstTimePassRate returns the share of MOTs passed without a retest, between 0 and 1
+func (r *Reliability) FirstTimePassRate() (float64, bool) {
+	if r.FirstAttempts == 0 {
+		return 0, false
+	}
+	return float64(r.FirstTimePasses) / float64(r.FirstAttempts), true
+}
+
+// FailuresPerYear returns the average number of failed tests per year of MOT history
+func (r *Reliability) FailuresPerYear() (float64, bool) {
+	if r.Years == 0 {
+		return 0, false
+	}
+	return float64(r.Failures) / r.Years, true
+}
+
+// Verdict returns a plain summary of the MOT record
+func (r *Reliability) Verdict() string {
+	rate, ok := r.FirstTimePassRate()
+	switch {
+	case !ok:
+		return "No MOT tests to judge"
+	case rate >= 0.8 && len(r.Recurring) == 0:
+		return "Good MOT record"
+	case rate >= 0.5:
+		return "Average MOT record"
+	default:
+		return "Poor MOT record, fails often"
+	}
+}
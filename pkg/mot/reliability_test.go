@@ -0,0 +1,55 @@
+package mot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyseReliability(t *testing.T) {
+	tests := []MotTest{
+		{CompletedDate: testDate("2020-05-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "20000", OdometerUnit: "MI", OdometerResultType: "READ", Defects: []Defect{
+			{Text: "Nearside Front Tyre worn close to legal limit (5.2.3 (e))", Type: "ADVISORY"},
+		}},
+		{CompletedDate: testDate("2021-05-01T10:00:00Z"), TestResult: "FAILED", OdometerValue: "30000", OdometerUnit: "MI", OdometerResultType: "READ", Defects: []Defect{
+			{Text: "Offside Rear Tyre tread depth below requirements of 1.6mm (5.2.3 (e))", Type: "MAJOR"},
+			{Text: "Nearside Front Brake pipe corroded (1.1.11 (c))", Type: "MAJOR"},
+			{Text: "Wiper blade deteriorated", Type: "USER ENTERED"},
+		}},
+		{CompletedDate: testDate("2021-05-08T10:00:00Z"), TestResult: "PASSED", OdometerValue: "30050", OdometerUnit: "MI", OdometerResultType: "READ"},
+		{CompletedDate: testDate("2022-05-01T10:00:00Z"), TestResult: "PASSED", OdometerValue: "40000", OdometerUnit: "MI", OdometerResultType: "READ", Defects: []Defect{
+			{Text: "Offside Front Shock absorber has a light misting of oil (5.3.2 (b))", Type: "ADVISORY"},
+		}},
+	}
+
+	r := AnalyseReliability(tests)
+
+	assert.Equal(t, 4, r.Tests)
+	assert.Equal(t, 1, r.Failures)
+	assert.Equal(t, 3, r.FirstAttempts)
+	assert.Equal(t, 2, r.FirstTimePasses)
+
+	rate, ok := r.FirstTimePassRate()
+	require.True(t, ok)
+	assert.InDelta(t, 0.667, rate, 0.001)
+
+	perYear, ok := r.FailuresPerYear()
+	require.True(t, ok)
+	assert.InDelta(t, 1/3.0, perYear, 0.01)
+
+	assert.Equal(t, []RecurringArea{{Area: SystemTyres, MOTs: 2}}, r.Recurring)
+	assert.InDelta(t, 10000, r.MilesPerYear, 10)
+	assert.Equal(t, "Average MOT record", r.Verdict())
+}
+
+func TestAnalyseReliability_NoTests(t *testing.T) {
+	r := AnalyseReliability(nil)
+
+	_, ok := r.FirstTimePassRate()
+	assert.False(t, ok)
+	_, ok = r.FailuresPerYear()
+	assert.False(t, ok)
+	assert.Zero(t, r.MilesPerYear)
+	assert.Equal(t, "No MOT tests to judge", r.Verdict())
+}
@@ -0,0 +1,53 @@
+package mot
+
+import (
+	"strings"
+	"time"
+)
+
+// PassRate returns the share of MOT tests that were passed, between 0 and 1.
+// It reports false if the vehicle has no tests.
+func (v *VehicleResponse) PassRate() (float64, bool) {
+	if len(v.MotTests) == 0 {
+		return 0, false
+	}
+
+	passed := 0
+	for _, test := range v.MotTests {
+		if strings.EqualFold(test.TestResult, "PASSED") {
+			passed++
+		}
+	}
+	return float64(passed) / float64(len(v.MotTests)), true
+}
+
+// AdvisoryCount returns the number of advisories recorded across all MOT tests
+func (v *VehicleResponse) AdvisoryCount() int {
+	count := 0
+	for _, test := range v.MotTests {
+		for _, defect := range test.Defects {
+			if defect.Severity() == DefectAdvisory {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// DangerousCount returns the number of dangerous defects recorded across all MOT tests
+func (v *VehicleResponse) DangerousCount() int {
+	count := 0
+	for _, test := range v.MotTests {
+		for _, defect := range test.Defects {
+			if defect.Severity() == DefectDangerous {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// FirstUsed returns the date the vehicle was first used
+func (v *VehicleResponse) FirstUsed() (time.Time, bool) {
+	return v.FirstUsedDate.Time, !v.FirstUsedDate.IsZero()
+}
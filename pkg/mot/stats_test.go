@@ -0,0 +1,45 @@
+package mot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleResponse_Stats(t *testing.T) {
+	vehicle := VehicleResponse{
+		FirstUsedDate: testDate("2015.06.01"),
+		MotTests: []MotTest{
+			{CompletedDate: testDate("2021-06-01T10:00:00Z"), TestResult: "FAILED", Defects: []Defect{
+				{Text: "Brake pipe corroded", Type: "MAJOR", Dangerous: true},
+				{Text: "Tyre worn close to legal limit", Type: "ADVISORY"},
+			}},
+			{CompletedDate: testDate("2021-06-03T10:00:00Z"), TestResult: "PASSED", Defects: []Defect{
+				{Text: "Tyre worn close to legal limit", Type: "ADVISORY"},
+			}},
+			{CompletedDate: testDate("2022-06-01T10:00:00Z"), TestResult: "PASSED", Defects: []Defect{
+				// Some records only mark a dangerous defect by its type
+				{Text: "Tyre cord exposed", Type: "DANGEROUS"},
+			}},
+			{CompletedDate: testDate("2023-06-01T10:00:00Z"), TestResult: "PASSED"},
+		},
+	}
+
+	rate, ok := vehicle.PassRate()
+	assert.True(t, ok)
+	assert.InDelta(t, 0.75, rate, 0.001)
+	assert.Equal(t, 2, vehicle.AdvisoryCount())
+	assert.Equal(t, 2, vehicle.DangerousCount())
+
+	firstUsed, ok := vehicle.FirstUsed()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC), firstUsed)
+}
+
+func TestVehicleResponse_PassRateNoTests(t *testing.T) {
+	vehicle := VehicleResponse{}
+
+	_, ok := vehicle.PassRate()
+	assert.False(t, ok)
+}
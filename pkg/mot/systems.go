@@ -0,0 +1,134 @@
+package mot
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Vehicle systems that defects are classified into
+const (
+	SystemTyres      = "Tyres"
+	SystemBrakes     = "Brakes"
+	SystemSteering   = "Steering"
+	SystemSuspension = "Suspension"
+	SystemLighting   = "Lighting"
+	SystemEmissions  = "Emissions"
+	SystemCorrosion  = "Corrosion"
+	SystemOther      = "Other"
+)
+
+// systemRules classify a defect by its wording. The first matching rule
+// wins, so a corroded brake pipe is a brake defect rather than corrosion.
+var systemRules = []struct {
+	system  string
+	pattern *regexp.Regexp
+}{
+	{SystemTyres, regexp.MustCompile(`\btyres?\b|\btread\b|sidewall|\bwheel (nuts?|bolts?|studs?)\b`)},
+	{SystemBrakes, regexp.MustCompile(`brak(e|es|ing)\b|\bdiscs?\b|\bpads?\b|\bcalipers?\b|\babs\b|\bservo\b|master cylinder`)},
+	{SystemSteering, regexp.MustCompile(`steering|track rod|tie rod|\bracks?\b|drag link|king ?pin`)},
+	{SystemSuspension, regexp.MustCompile(`suspension|shock absorber|\bdampers?\b|\bsprings?\b|ball joint|wishbone|\bbush(es|ing|ings)?\b|anti-roll|stabiliser|wheel bearing|drive ?shaft|\bcv\b|\bgaiters?\b|\barms?\b`)},
+	{SystemLighting, regexp.MustCompile(`lamps?\b|\blights?\b|headlamp|\bindicators?\b|reflector|\bbulbs?\b|\bbeam\b`)},
+	{SystemEmissions, regexp.MustCompile(`emission|exhaust|smoke|hydrocarbon|\bco\b|lambda|catalyst|catalytic|\bdpf\b|particulate|opacity|silencer`)},
+	{SystemCorrosion, regexp.MustCompile(`corro|\brust|\bsills?\b|sub-?frame|rotted`)},
+}
+
+// System returns the vehicle system a defect belongs to, or SystemOther if no rule matches
+func (d Defect) System() string {
+	text := strings.ToLower(d.Text)
+	for _, rule := range systemRules {
+		if rule.pattern.MatchString(text) {
+			return rule.system
+		}
+	}
+	return SystemOther
+}
+
+// SystemEntry is a single defect in the history of a system
+type SystemEntry struct {
+	Date    time.Time
+	Text    string
+	Type    DefectType
+	Failure bool
+}
+
+// SystemSummary counts the defects of a single system across MOT tests
+type SystemSummary struct {
+	System           string
+	Defects          int
+	Failures         int
+	TestsWithDefects int
+	FailedTests      int
+	Entries          []SystemEntry
+}
+
+// SystemReport is the defect history of a vehicle grouped by system
+type SystemReport struct {
+	Tests   int
+	Systems []SystemSummary
+}
+
+// AnalyseSystems groups the defects of the MOT tests by vehicle system. The
+// systems are ordered by the number of tests they failed, then by the number
+// of tests they had defects in.
+func AnalyseSystems(tests []MotTest) SystemReport {
+	sorted := make([]MotTest, len(tests))
+	copy(sorted, tests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CompletedDate.Before(sorted[j].CompletedDate.Time)
+	})
+
+	report := SystemReport{Tests: len(sorted)}
+	summaries := make(map[string]*SystemSummary)
+
+	for _, test := range sorted {
+		date := test.CompletedDate.Time
+		seen := make(map[string]bool)
+		failed := make(map[string]bool)
+
+		for _, defect := range test.Defects {
+			system := defect.System()
+			s, ok := summaries[system]
+			if !ok {
+				s = &SystemSummary{System: system}
+				summaries[system] = s
+			}
+
+			failure := defect.IsFailure()
+			s.Defects++
+			if failure {
+				s.Failures++
+			}
+			s.Entries = append(s.Entries, SystemEntry{Date: date, Text: defect.Text, Type: defect.Severity(), Failure: failure})
+
+			if !seen[system] {
+				seen[system] = true
+				s.TestsWithDefects++
+			}
+			if failure && !failed[system] {
+				failed[system] = true
+				s.FailedTests++
+			}
+		}
+	}
+
+	for _, s := range summaries {
+		report.Systems = append(report.Systems, *s)
+	}
+	sort.Slice(report.Systems, func(i, j int) bool {
+		a, b := report.Systems[i], report.Systems[j]
+		if (a.System == SystemOther) != (b.System == SystemOther) {
+			return b.System == SystemOther
+		}
+		if a.FailedTests != b.FailedTests {
+			return a.FailedTests > b.FailedTests
+		}
+		if a.TestsWithDefects != b.TestsWithDefects {
+			return a.TestsWithDefects > b.TestsWithDefects
+		}
+		return a.System < b.System
+	})
+
+	return report
+}
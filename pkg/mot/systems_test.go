@@ -0,0 +1,65 @@
+package mot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefect_System(t *testing.T) {
+	cases := map[string]string{
+		"Nearside Front Tyre worn close to legal limit (5.2.3 (e))":                        SystemTyres,
+		"Offside Rear Brake pipe corroded, covering removed (1.1.11 (c))":                  SystemBrakes,
+		"Nearside Front Brake disc worn, pitted or scored, but not seriously weakened":     SystemBrakes,
+		"Offside Track rod end ball joint has slight play (2.1.3 (b))":                     SystemSteering,
+		"Nearside Front Coil spring broken (5.3.1 (b) (i))":                                SystemSuspension,
+		"Offside Front Shock absorber has a light misting of oil (5.3.2 (b))":              SystemSuspension,
+		"Headlamp aim too high (4.1.2 (a) (ii))":                                           SystemLighting,
+		"Offside Rear Registration plate lamp inoperative (4.7.1 (b) (ii))":                SystemLighting,
+		"Exhaust emits an excessive quantity of visible smoke (8.2.2.2 (a))":               SystemEmissions,
+		"Nearside Sill corroded but not seriously weakened":                                SystemCorrosion,
+		"Windscreen has damage to an area less than a 10mm circle within zone A (3.2 (a))": SystemOther,
+	}
+
+	for text, want := range cases {
+		assert.Equal(t, want, Defect{Text: text}.System(), text)
+	}
+}
+
+func TestAnalyseSystems(t *testing.T) {
+	tests := []MotTest{
+		{CompletedDate: testDate("2021-05-01T10:00:00Z"), TestResult: "FAILED", Defects: []Defect{
+			{Text: "Nearside Front Brake pipe corroded (1.1.11 (c))", Type: "MAJOR"},
+			{Text: "Offside Front Brake disc excessively worn (1.1.14 (a) (ii))", Type: "MAJOR"},
+			{Text: "Nearside Front Tyre worn close to legal limit (5.2.3 (e))", Type: "ADVISORY"},
+		}},
+		{CompletedDate: testDate("2021-05-03T10:00:00Z"), TestResult: "PASSED", Defects: []Defect{
+			{Text: "Nearside Front Tyre worn close to legal limit (5.2.3 (e))", Type: "ADVISORY"},
+		}},
+		{CompletedDate: testDate("2022-05-01T10:00:00Z"), TestResult: "FAILED", Defects: []Defect{
+			{Text: "Nearside Rear Brake pad(s) less than 1.5 mm thick (1.1.13 (a) (ii))", Type: "MAJOR"},
+			{Text: "Windscreen wiper blade defective", Type: "USER ENTERED"},
+		}},
+	}
+
+	report := AnalyseSystems(tests)
+
+	assert.Equal(t, 3, report.Tests)
+	require.Len(t, report.Systems, 3)
+
+	brakes := report.Systems[0]
+	assert.Equal(t, SystemBrakes, brakes.System)
+	assert.Equal(t, 3, brakes.Defects)
+	assert.Equal(t, 3, brakes.Failures)
+	assert.Equal(t, 2, brakes.TestsWithDefects)
+	assert.Equal(t, 2, brakes.FailedTests)
+	assert.Len(t, brakes.Entries, 3)
+
+	tyres := report.Systems[1]
+	assert.Equal(t, SystemTyres, tyres.System)
+	assert.Equal(t, 2, tyres.TestsWithDefects)
+	assert.Zero(t, tyres.FailedTests)
+
+	assert.Equal(t, SystemOther, report.Systems[2].System)
+}
@@ -0,0 +1,196 @@
+// Package pdf writes simple PDF documents of text, lines and shaded boxes
+// using the standard Helvetica fonts, which every PDF reader provides, so no
+// fonts need to be embedded.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A4 page size in points
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font is one of the standard fonts
+type Font string
+
+// Standard fonts
+const (
+	FontRegular Font = "F1"
+	FontBold    Font = "F2"
+)
+
+// fontNames are the base font names of the standard fonts
+var fontNames = map[Font]string{
+	FontRegular: "Helvetica",
+	FontBold:    "Helvetica-Bold",
+}
+
+// Document is a PDF document made of pages
+type Document struct {
+	Title     string
+	Author    string
+	CreatedAt time.Time
+
+	pages []*Page
+}
+
+// Page is a single A4 page. Coordinates are in points from the bottom left corner.
+type Page struct {
+	content bytes.Buffer
+}
+
+// New returns an empty document
+func New(title string) *Document {
+	return &Document{Title: title, CreatedAt: time.Now()}
+}
+
+// AddPage adds an empty page to the end of the document
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the pages of the document in order
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Text draws a line of text with its baseline starting at (x, y)
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(encode(text)))
+}
+
+// Line draws a straight line
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// FillRect fills a rectangle with a shade of grey, from 0 for black to 1 for white
+func (p *Page) FillRect(x, y, w, h, grey float64) {
+	fmt.Fprintf(&p.content, "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", grey, x, y, w, h)
+}
+
+// WriteTo writes the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+
+	// Objects are numbered from 1 in the order they are written
+	startObject := func() int {
+		offsets = append(offsets, buf.Len())
+		n := len(offsets)
+		fmt.Fprintf(&buf, "%d 0 obj\n", n)
+		return n
+	}
+	endObject := func() {
+		buf.WriteString("endobj\n")
+	}
+
+	// Fixed objects: 1 catalog, 2 page tree, 3 and 4 fonts, 5 info, then a page and its content per page
+	const firstPageObject = 6
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	startObject()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\n")
+	endObject()
+
+	startObject()
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+2*i)
+	}
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(d.pages))
+	endObject()
+
+	for _, font := range []Font{FontRegular, FontBold} {
+		startObject()
+		fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\n", fontNames[font])
+		endObject()
+	}
+
+	startObject()
+	fmt.Fprintf(&buf, "<< /Title (%s) /Author (%s) /Producer (mot-bot) /CreationDate (D:%s) >>\n",
+		escape(encode(d.Title)), escape(encode(d.Author)), d.CreatedAt.UTC().Format("20060102150405Z"))
+	endObject()
+
+	for i, page := range d.pages {
+		startObject()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>\n",
+			A4Width, A4Height, firstPageObject+2*i+1)
+		endObject()
+
+		startObject()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n", page.content.Len())
+		buf.Write(page.content.Bytes())
+		buf.WriteString("endstream\n")
+		endObject()
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// Bytes returns the document as a PDF file
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	// Writing to a bytes.Buffer can't fail
+	_, _ = d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// encode converts text to WinAnsiEncoding. Characters it can't represent,
+// such as emoji, are dropped.
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		case r == '\t':
+			out = append(out, ' ')
+		case r == '–', r == '—':
+			out = append(out, '-')
+		case r == '‘', r == '’':
+			out = append(out, '\'')
+		case r == '“', r == '”':
+			out = append(out, '"')
+		case r == '…':
+			out = append(out, '.', '.', '.')
+		case r == '•':
+			out = append(out, 0x95)
+		}
+	}
+	return out
+}
+
+// escape escapes the characters that are special inside a PDF string
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
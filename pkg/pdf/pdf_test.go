@@ -0,0 +1,49 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_Bytes(t *testing.T) {
+	doc := New("Report (test)")
+	page := doc.AddPage()
+	page.Text(50, 800, FontBold, 16, "Vehicle AB12CDE 🚗")
+	page.Line(50, 790, 545, 790, 1)
+	doc.AddPage().Text(50, 800, FontRegular, 10, `Costs £100 (approx) \ path`)
+
+	data := doc.Bytes()
+
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	assert.Contains(t, string(data), "/Count 2")
+	assert.Contains(t, string(data), "(Vehicle AB12CDE ) Tj")
+	assert.Contains(t, string(data), "(Costs \xa3100 \\(approx\\) \\\\ path) Tj")
+	assert.Contains(t, string(data), "/Title (Report \\(test\\))")
+
+	// The cross-reference table must point at the start of every object
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, match)
+	xref, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	require.Len(t, entries, 9)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "object %d", i+1)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.InDelta(t, 5.56, TextWidth(FontRegular, 10, "0"), 0.001)
+	assert.InDelta(t, 27.8*2, TextWidth(FontRegular, 100, "ii"), 22.3)
+	assert.Greater(t, TextWidth(FontBold, 10, "Brakes"), TextWidth(FontRegular, 10, "Brakes"))
+}
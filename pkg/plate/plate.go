@@ -0,0 +1,68 @@
+package plate
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Format is the numbering scheme a registration number belongs to
+type Format string
+
+// Registration number formats
+const (
+	FormatCurrent         Format = "current"
+	FormatPrefix          Format = "prefix"
+	FormatSuffix          Format = "suffix"
+	FormatDateless        Format = "dateless"
+	FormatNorthernIreland Format = "northern_ireland"
+)
+
+var (
+	ErrEmpty   = errors.New("registration number is empty")
+	ErrInvalid = errors.New("not a valid UK registration number")
+)
+
+var (
+	currentPattern     = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z]{3}$`)
+	prefixPattern      = regexp.MustCompile(`^[A-Z][0-9]{1,3}[A-Z]{3}$`)
+	suffixPattern      = regexp.MustCompile(`^[A-Z]{3}[0-9]{1,3}[A-Z]$`)
+	letterFirstPattern = regexp.MustCompile(`^[A-Z]{1,3}[0-9]{1,4}$`)
+	digitFirstPattern  = regexp.MustCompile(`^[0-9]{1,4}[A-Z]{1,3}$`)
+)
+
+// Normalise upper-cases a registration number and strips spaces and punctuation
+func Normalise(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// Parse normalises a registration number and checks it against the GB and
+// Northern Ireland numbering formats
+func Parse(s string) (string, Format, error) {
+	reg := Normalise(s)
+	if reg == "" {
+		return "", "", ErrEmpty
+	}
+
+	switch {
+	case currentPattern.MatchString(reg):
+		return reg, FormatCurrent, nil
+	case prefixPattern.MatchString(reg):
+		return reg, FormatPrefix, nil
+	case suffixPattern.MatchString(reg):
+		return reg, FormatSuffix, nil
+	case letterFirstPattern.MatchString(reg) && strings.ContainsAny(strings.TrimRight(reg, "0123456789"), "IZ"):
+		// Northern Ireland marks are the only ones that use the letters I and Z
+		return reg, FormatNorthernIreland, nil
+	case letterFirstPattern.MatchString(reg), digitFirstPattern.MatchString(reg):
+		return reg, FormatDateless, nil
+	default:
+		return reg, "", ErrInvalid
+	}
+}
@@ -0,0 +1,40 @@
+package plate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalise(t *testing.T) {
+	assert.Equal(t, "AB12CDE", Normalise(" ab12 cde "))
+	assert.Equal(t, "AB12CDE", Normalise("AB-12.CDE"))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		reg    string
+		format Format
+		err    error
+	}{
+		{"ab12 cde", "AB12CDE", FormatCurrent, nil},
+		{"A123 BCD", "A123BCD", FormatPrefix, nil},
+		{"ABC 123D", "ABC123D", FormatSuffix, nil},
+		{"ABC 1234", "ABC1234", FormatDateless, nil},
+		{"1234 AB", "1234AB", FormatDateless, nil},
+		{"AIZ 1234", "AIZ1234", FormatNorthernIreland, nil},
+		{"hello", "HELLO", "", ErrInvalid},
+		{"AB12CDEF", "AB12CDEF", "", ErrInvalid},
+		{"  ", "", "", ErrEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			reg, format, err := Parse(tt.input)
+			assert.Equal(t, tt.reg, reg)
+			assert.Equal(t, tt.format, format)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
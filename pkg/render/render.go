@@ -0,0 +1,543 @@
+// Package render writes the sections of a vehicle lookup, such as the
+// vehicle details, reliability, tax, clean air estimate and MOT history, as
+// Telegram Markdown or as plain text, so the bot and the command line tool
+// show the same analysis.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mot-bot/pkg/cleanair"
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+)
+
+// Style is the markup the sections are written in
+type Style int
+
+// Styles
+const (
+	// Markdown is Telegram's legacy Markdown
+	Markdown Style = iota
+
+	// Plain is text without markup, for terminals and files
+	Plain
+)
+
+// Writer writes sections to a strings.Builder in a style
+type Writer struct {
+	sb    *strings.Builder
+	style Style
+}
+
+// NewWriter returns a writer that appends to sb
+func NewWriter(sb *strings.Builder, style Style) *Writer {
+	return &Writer{sb: sb, style: style}
+}
+
+// Full renders every section of a lookup result
+func Full(result *lookup.Result, style Style) string {
+	var sb strings.Builder
+	w := NewWriter(&sb, style)
+
+	w.Alerts(result)
+	w.Reliability(result)
+	w.Vehicle(result)
+	w.KnownIssues(result)
+	w.Tax(result)
+	w.CleanAir(result)
+	w.Mileage(result)
+	w.Systems(result)
+	w.History(result)
+	w.FetchedAt(result)
+
+	return sb.String()
+}
+
+// markupChars are the characters legacy Markdown treats as markup
+const markupChars = "_*`["
+
+// Text escapes the markup characters in s, such as the underscores of DVSA
+// codes, so upstream text is shown as written
+func (w *Writer) Text(s string) string {
+	if w.style == Plain || !strings.ContainsAny(s, markupChars) {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(markupChars, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Bold marks up s as bold. Legacy Markdown can't escape inside an entity, so
+// text containing markup characters is escaped and left unformatted instead.
+func (w *Writer) Bold(s string) string {
+	if w.style == Plain || strings.ContainsAny(s, markupChars) {
+		return w.Text(s)
+	}
+	return "*" + s + "*"
+}
+
+// Italic marks up s as italic, or escapes it like Bold if it contains markup characters
+func (w *Writer) Italic(s string) string {
+	if w.style == Plain || strings.ContainsAny(s, markupChars) {
+		return w.Text(s)
+	}
+	return "_" + s + "_"
+}
+
+// Code marks up s as a value in monospace. Only a backtick ends the value
+// early, so backticks in s are replaced with quotes.
+func (w *Writer) Code(s string) string {
+	if w.style == Plain {
+		return s
+	}
+	return "`" + strings.ReplaceAll(s, "`", "'") + "`"
+}
+
+// Printf appends formatted text
+func (w *Writer) Printf(format string, args ...any) {
+	w.sb.WriteString(fmt.Sprintf(format, args...))
+}
+
+// heading writes the title of a section
+func (w *Writer) heading(emoji, title string) {
+	w.Printf("%s %s\n\n", emoji, w.Bold(title))
+}
+
+// field writes a labelled value on its own line
+func (w *Writer) field(emoji, label string, value any) {
+	w.Printf("%s %s %s\n", emoji, w.Bold(label+":"), w.Code(fmt.Sprint(value)))
+}
+
+// note writes a line of explanation
+func (w *Writer) note(emoji, text string) {
+	w.Printf("%s %s\n", emoji, w.Italic(text))
+}
+
+// Alerts writes the warnings that must not be missed above everything
+// else: an outstanding safety recall, a vehicle marked for export and the
+// first MOT due date of a new vehicle
+func (w *Writer) Alerts(result *lookup.Result) {
+	written := false
+	if result.VES != nil && result.VES.MarkedForExport {
+		w.Printf("🚢 %s The DVLA has been told this vehicle is leaving the UK.\n", w.Bold("Marked for export!"))
+		written = true
+	}
+	if result.MOT != nil && result.MOT.OutstandingRecall() {
+		w.Printf("🚨 %s Contact a dealer for this make to have it fixed free of charge.\n", w.Bold("Outstanding safety recall!"))
+		written = true
+	}
+	if result.MOT != nil && len(result.MOT.MotTests) == 0 && !result.MOT.MotTestDueDate.IsZero() {
+		line := fmt.Sprintf("📅 %s %s", w.Bold("First MOT due:"), w.Code(result.MOT.MotTestDueDate.String()))
+		if result.MOT.MotTestDueDate.Before(time.Now()) {
+			line += " ⚠️ " + w.Italic("overdue")
+		}
+		w.Printf("%s\n", line)
+		written = true
+	}
+	if written {
+		w.Printf("\n")
+	}
+}
+
+// Vehicle writes the basic vehicle details from whichever API returned
+// them, falling back to the DVLA details if the MOT history is unavailable
+func (w *Writer) Vehicle(result *lookup.Result) {
+	motVehicle, vesVehicle := result.MOT, result.VES
+
+	w.heading("🚗", "Vehicle Information")
+	switch {
+	case motVehicle != nil:
+		w.field("📝", "Registration", motVehicle.Registration)
+		w.field("🏭", "Make", motVehicle.Make)
+		w.field("🚘", "Model", motVehicle.Model)
+		w.field("📅", "First Registered", motVehicle.FirstUsedDate)
+		w.field("⛽", "Fuel Type", motVehicle.FuelType)
+		w.field("🎨", "Colour", motVehicle.PrimaryColour)
+		if motVehicle.HasOutstandingRecall != "" {
+			w.field("🛡", "Outstanding Recall", motVehicle.HasOutstandingRecall)
+		}
+	case vesVehicle != nil:
+		w.field("📝", "Registration", vesVehicle.RegistrationNumber)
+		w.field("🏭", "Make", vesVehicle.Make)
+		if !vesVehicle.MonthOfFirstRegistration.IsZero() {
+			w.field("📅", "First Registered", vesVehicle.MonthOfFirstRegistration)
+		}
+		w.field("⛽", "Fuel Type", vesVehicle.FuelType)
+		w.field("🎨", "Colour", vesVehicle.Colour)
+	default:
+		w.field("📝", "Registration", result.Registration)
+	}
+
+	if vesVehicle != nil {
+		if vesVehicle.YearOfManufacture > 0 {
+			w.field("🏗", "Year of Manufacture", vesVehicle.YearOfManufacture)
+		}
+		if vesVehicle.EngineCapacity > 0 {
+			w.field("🔋", "Engine Capacity", fmt.Sprintf("%d cc", vesVehicle.EngineCapacity))
+		}
+		if vesVehicle.Co2Emissions > 0 {
+			w.field("🌫", "CO2 Emissions", fmt.Sprintf("%d g/km", vesVehicle.Co2Emissions))
+		}
+		w.field("🛞", "Wheelplan", vesVehicle.Wheelplan)
+		w.field("🌍", "Euro Status", vesVehicle.EuroStatus)
+		if vesVehicle.RealDrivingEmissions != "" {
+			w.field("🧪", "Real Driving Emissions", vesVehicle.RealDrivingEmissions)
+		}
+		if vesVehicle.TypeApproval != "" {
+			w.field("📋", "Type Approval", vesVehicle.TypeApproval)
+		}
+		if vesVehicle.RevenueWeight > 0 {
+			w.field("⚖️", "Revenue Weight", fmt.Sprintf("%d kg", vesVehicle.RevenueWeight))
+		}
+		if !vesVehicle.DateOfLastV5CIssued.IsZero() {
+			w.field("📄", "Last V5C Issued", vesVehicle.DateOfLastV5CIssued)
+		}
+	}
+}
+
+// Reliability writes the verdict and metrics derived from the MOT history
+func (w *Writer) Reliability(result *lookup.Result) {
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		return
+	}
+
+	r := mot.AnalyseReliability(result.MOT.MotTests)
+	w.heading("🩺", "Reliability")
+	w.field(ReliabilityEmoji(&r), "Verdict", r.Verdict())
+	if rate, ok := r.FirstTimePassRate(); ok {
+		w.Printf("✅ %s %s (%d of %d MOTs)\n", w.Bold("First-time pass rate:"), w.Code(fmt.Sprintf("%.0f%%", rate*100)), r.FirstTimePasses, r.FirstAttempts)
+	}
+	if perYear, ok := r.FailuresPerYear(); ok {
+		w.field("❌", "Failures per year", fmt.Sprintf("%.1f", perYear))
+	}
+	if r.MilesPerYear > 0 {
+		w.field("📏", "Average mileage", fmt.Sprintf("%d miles per year", r.MilesPerYear))
+	}
+	if len(r.Recurring) > 0 {
+		w.Printf("🔁 %s\n", w.Bold("Recurring problems:"))
+		for _, area := range r.Recurring {
+			w.Printf("  • %s at %d MOTs\n", w.Code(area.Area), area.MOTs)
+		}
+	}
+	w.Printf("\n")
+}
+
+// ReliabilityEmoji returns the emoji matching a reliability verdict
+func ReliabilityEmoji(r *mot.Reliability) string {
+	rate, ok := r.FirstTimePassRate()
+	switch {
+	case !ok:
+		return "ℹ️"
+	case rate >= 0.8 && len(r.Recurring) == 0:
+		return "🟢"
+	case rate >= 0.5:
+		return "🟡"
+	default:
+		return "🔴"
+	}
+}
+
+// KnownIssues writes the advisories that were repeated without being fixed
+// or that later failed the vehicle
+func (w *Writer) KnownIssues(result *lookup.Result) {
+	if result.MOT == nil {
+		return
+	}
+	issues := mot.FindKnownIssues(result.MOT.MotTests)
+	if len(issues) == 0 {
+		return
+	}
+
+	w.Printf("\n")
+	w.heading("🧰", "Known Issues")
+	for _, issue := range issues {
+		if !issue.FailedOn.IsZero() {
+			w.Printf("❌ %s\n  advised %s, failed %s: %s\n", w.Code(issue.Text),
+				w.Code(issue.FirstSeen.Format("02.01.2006")), w.Code(issue.FailedOn.Format("02.01.2006")), w.Italic(issue.FailureText))
+			continue
+		}
+		w.Printf("🔁 %s\n  advised at %d MOTs since %s, still not fixed\n", w.Code(issue.Text), issue.MOTs, w.Code(issue.FirstSeen.Format("02.01.2006")))
+	}
+}
+
+// Tax writes the DVLA tax status, or notes that it is unavailable
+func (w *Writer) Tax(result *lookup.Result) {
+	w.Printf("\n")
+	w.heading("💰", "Tax Information")
+	if result.VES == nil {
+		w.note("⚠️", "Tax information is unavailable right now")
+		return
+	}
+
+	w.field("📊", "Status", result.VES.TaxStatus)
+	if !result.VES.TaxDueDate.IsZero() {
+		w.field("📅", "Due Date", result.VES.TaxDueDate)
+	}
+	if !result.VES.ArtEndDate.IsZero() {
+		w.field("💷", "Additional Rate Ends", result.VES.ArtEndDate)
+	}
+}
+
+// CleanAir writes the estimated London ULEZ and Clean Air Zone compliance
+// with the reason for each zone
+func (w *Writer) CleanAir(result *lookup.Result) {
+	w.Printf("\n🌿 %s %s\n\n", w.Bold("Clean Air Zones"), w.Italic("(estimate)"))
+	for _, a := range cleanair.Assess(cleanAirVehicle(result)) {
+		// The reason can include the upstream type approval, so it is escaped
+		w.Printf("%s %s %s\n  %s %s\n", CleanAirEmoji(a.Status), w.Bold(a.Zone.Name+":"), w.Code(string(a.Status)),
+			w.Text(a.Reason), w.Italic("("+a.Zone.Cities+")"))
+	}
+}
+
+// CleanAirVerdict writes a single line with the overall clean air estimate
+// and the zones it doesn't hold for
+func (w *Writer) CleanAirVerdict(result *lookup.Result) {
+	assessments := cleanair.Assess(cleanAirVehicle(result))
+	overall := cleanair.Overall(assessments)
+
+	where := "in every zone"
+	if overall != cleanair.StatusCompliant {
+		var zones []string
+		for _, a := range assessments {
+			if a.Status == overall {
+				zones = append(zones, a.Zone.Name)
+			}
+		}
+		where = "for " + strings.Join(zones, ", ")
+	}
+	w.Printf("🌿 %s %s %s %s %s\n", w.Bold("Clean air:"), CleanAirEmoji(overall), w.Code(string(overall)), where, w.Italic("(estimate)"))
+}
+
+// CleanAirEmoji returns the emoji matching a clean air estimate
+func CleanAirEmoji(status cleanair.Status) string {
+	switch status {
+	case cleanair.StatusCompliant:
+		return "✅"
+	case cleanair.StatusNonCompliant:
+		return "❌"
+	default:
+		return "❔"
+	}
+}
+
+// cleanAirVehicle collects the details the clean air estimate needs from both APIs
+func cleanAirVehicle(result *lookup.Result) cleanair.Vehicle {
+	var v cleanair.Vehicle
+	if result.MOT != nil {
+		v.FuelType = result.MOT.FuelType
+		v.FirstRegistered = result.MOT.FirstUsedDate.Time
+		if v.FirstRegistered.IsZero() {
+			v.FirstRegistered = result.MOT.RegistrationDate.Time
+		}
+	}
+	if result.VES != nil {
+		v.EuroStatus = result.VES.EuroStatus
+		v.TypeApproval = result.VES.TypeApproval
+		if v.FuelType == "" {
+			v.FuelType = result.VES.FuelType
+		}
+		if v.FirstRegistered.IsZero() {
+			v.FirstRegistered = result.VES.MonthOfFirstRegistration.Time
+		}
+	}
+	return v
+}
+
+// Mileage writes the mileage check verdict and any anomalies found
+func (w *Writer) Mileage(result *lookup.Result) {
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		return
+	}
+
+	w.Printf("\n")
+	w.heading("🔎", "Mileage Check")
+	mileage := mot.AnalyseMileage(result.MOT.MotTests)
+	w.field(MileageEmoji(&mileage), "Verdict", mileage.Verdict())
+	for _, anomaly := range mileage.Anomalies {
+		w.Printf("  ⚠️ %s %s\n", w.Code(anomaly.Date.Format("02.01.2006")), w.Text(anomaly.Description))
+	}
+}
+
+// MileageEmoji returns the emoji matching the severity of a mileage check
+func MileageEmoji(mileage *mot.MileageReport) string {
+	switch {
+	case len(mileage.Readings) == 0:
+		return "ℹ️"
+	case mileage.HasRollback():
+		return "🚨"
+	case len(mileage.Anomalies) > 0:
+		return "⚠️"
+	default:
+		return "✅"
+	}
+}
+
+// SystemEmoji returns the emoji shown next to a vehicle system
+func SystemEmoji(system string) string {
+	switch system {
+	case mot.SystemTyres:
+		return "🛞"
+	case mot.SystemBrakes:
+		return "🛑"
+	case mot.SystemSteering:
+		return "🎯"
+	case mot.SystemSuspension:
+		return "🔩"
+	case mot.SystemLighting:
+		return "💡"
+	case mot.SystemEmissions:
+		return "💨"
+	case mot.SystemCorrosion:
+		return "🟤"
+	default:
+		return "🔧"
+	}
+}
+
+// SystemCounts writes how many tests each vehicle system had defects in and failed
+func (w *Writer) SystemCounts(report *mot.SystemReport) {
+	for _, s := range report.Systems {
+		line := fmt.Sprintf("%s %s %s defects in %d of %d tests", SystemEmoji(s.System), w.Bold(s.System+":"), w.Code(fmt.Sprint(s.Defects)), s.TestsWithDefects, report.Tests)
+		if s.FailedTests > 0 {
+			line += fmt.Sprintf(", failed %d", s.FailedTests)
+		}
+		w.Printf("%s\n", line)
+	}
+}
+
+// Systems writes the defect counts of each vehicle system followed by its history
+func (w *Writer) Systems(result *lookup.Result) {
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		return
+	}
+
+	report := mot.AnalyseSystems(result.MOT.MotTests)
+	w.Printf("\n")
+	w.heading("🛠", "Defects by System")
+	if len(report.Systems) == 0 {
+		w.note("✅", "No defects recorded in any MOT test")
+		return
+	}
+	w.SystemCounts(&report)
+
+	for _, s := range report.Systems {
+		w.Printf("\n%s %s\n", SystemEmoji(s.System), w.Bold(s.System))
+		for i := len(s.Entries) - 1; i >= 0; i-- {
+			entry := s.Entries[i]
+			w.Printf("  %s %s %s\n", w.Code(entry.Date.Format("02.01.2006")), DefectEmoji(entry.Type), w.Code(entry.Text))
+		}
+	}
+}
+
+// History writes every MOT test, or explains why there are none
+func (w *Writer) History(result *lookup.Result) {
+	w.Printf("\n")
+	w.heading("🔧", "MOT History")
+	switch {
+	case result.NoMOTRecord():
+		w.note("ℹ️", "No MOT record found. New vehicles don't need an MOT until they are 3 years old.")
+		return
+	case result.MOT == nil:
+		w.note("⚠️", "MOT history is unavailable right now")
+		// The DVLA keeps the MOT status too, so show that instead
+		if result.VES != nil && result.VES.MotStatus != "" {
+			w.field("🔧", "MOT Status (DVLA)", result.VES.MotStatus)
+			if !result.VES.MotExpiryDate.IsZero() {
+				w.field("📅", "MOT Expiry (DVLA)", result.VES.MotExpiryDate)
+			}
+		}
+		return
+	case len(result.MOT.MotTests) == 0 && !result.MOT.MotTestDueDate.IsZero():
+		w.note("ℹ️", fmt.Sprintf("No MOT tests yet, the first MOT is due on %s", result.MOT.MotTestDueDate))
+		return
+	case len(result.MOT.MotTests) == 0:
+		w.note("ℹ️", "No MOT tests recorded yet")
+		return
+	}
+
+	for _, test := range result.MOT.MotTests {
+		w.Test(result.Registration, test)
+		w.Printf("\n")
+	}
+}
+
+// Test writes a single MOT test with its defects. The registration is used
+// to point out tests done under a different registration.
+func (w *Writer) Test(registration string, test mot.MotTest) {
+	w.field("📅", "Test Date", test.CompletedDate)
+
+	resultEmoji := "✅"
+	if strings.ToUpper(test.TestResult) == "FAILED" {
+		resultEmoji = "❌"
+	}
+	w.field(resultEmoji, "Result", test.TestResult)
+
+	switch {
+	case test.OdometerValue != "" && !strings.EqualFold(test.OdometerResultType, mot.OdometerUnreadable) && !strings.EqualFold(test.OdometerResultType, mot.OdometerNone):
+		w.field("📏", "Mileage", fmt.Sprintf("%s %s", test.OdometerValue, test.OdometerUnit))
+	case test.OdometerResultType != "":
+		w.Printf("📏 %s %s\n", w.Bold("Mileage:"), w.Italic(test.OdometerResultDescription()))
+	}
+	if test.RegistrationAtTimeOfTest != "" && test.RegistrationAtTimeOfTest != registration {
+		w.field("🔁", "Registration at Test", test.RegistrationAtTimeOfTest)
+	}
+	if test.DataSource != "" && test.DataSource != mot.DataSourceDVSA {
+		w.field("🏛", "Tested by", test.DataSource)
+	}
+	if test.Location != "" {
+		w.field("📍", "Location", test.Location)
+	}
+	if len(test.Defects) > 0 {
+		w.Printf("⚠️ %s\n", w.Bold("Defects:"))
+		for _, defect := range mot.SortDefects(test.Defects) {
+			severity := defect.Severity()
+			w.Printf("  %s %s %s\n", DefectEmoji(severity), w.Italic(severity.Label()), w.Code(defect.Text))
+		}
+	}
+}
+
+// DefectEmoji returns the emoji shown next to a defect of the given type
+func DefectEmoji(t mot.DefectType) string {
+	switch t.Normalise() {
+	case mot.DefectDangerous:
+		return "🚨"
+	case mot.DefectMajor:
+		return "❌"
+	case mot.DefectFail:
+		return "⛔"
+	case mot.DefectPRS:
+		return "🔧"
+	case mot.DefectMinor:
+		return "🔸"
+	case mot.DefectAdvisory:
+		return "⚠️"
+	case mot.DefectNonSpecific:
+		return "🔹"
+	case mot.DefectUserEntered:
+		return "📝"
+	default:
+		return "ℹ️"
+	}
+}
+
+// FetchedAt writes when the data was retrieved, using the older of the two responses
+func (w *Writer) FetchedAt(result *lookup.Result) {
+	var fetchedAt time.Time
+	if result.MOT != nil {
+		fetchedAt = result.MOT.FetchedAt
+	}
+	if result.VES != nil && (fetchedAt.IsZero() || result.VES.FetchedAt.Before(fetchedAt)) {
+		fetchedAt = result.VES.FetchedAt
+	}
+	if !fetchedAt.IsZero() {
+		w.note("🕒", fmt.Sprintf("Data as of %s UTC", fetchedAt.UTC().Format("02.01.2006 15:04")))
+	}
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/ves"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testResult() *lookup.Result {
+	date := func(year int, month time.Month, day int) mot.Date {
+		return mot.Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+	}
+	return &lookup.Result{
+		Registration: "AB12CDE",
+		MOT: &mot.VehicleResponse{
+			Registration:  "AB12CDE",
+			Make:          "FORD",
+			Model:         "FOCUS",
+			FuelType:      "Petrol",
+			PrimaryColour: "Blue",
+			FirstUsedDate: date(2012, 3, 1),
+			MotTests: []mot.MotTest{
+				{
+					CompletedDate:      date(2024, 3, 1),
+					TestResult:         "PASSED",
+					OdometerValue:      "80000",
+					OdometerUnit:       "MI",
+					OdometerResultType: mot.OdometerRead,
+				},
+				{
+					CompletedDate:            date(2023, 3, 1),
+					TestResult:               "FAILED",
+					OdometerValue:            "70000",
+					OdometerUnit:             "MI",
+					OdometerResultType:       mot.OdometerRead,
+					RegistrationAtTimeOfTest: "OLD1",
+					Defects:                  []mot.Defect{{Text: "Brake pipe corroded", Type: mot.DefectMajor}},
+				},
+			},
+		},
+		VES: &ves.Vehicle{
+			RegistrationNumber: "AB12CDE",
+			TaxStatus:          "Taxed",
+			TaxDueDate:         ves.CustomTime{Time: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+			EuroStatus:         "EURO 4",
+			TypeApproval:       "M1",
+		},
+	}
+}
+
+func TestFull_Markdown(t *testing.T) {
+	text := Full(testResult(), Markdown)
+
+	assert.True(t, strings.HasPrefix(text, "🩺 *Reliability*\n\n"))
+	assert.Contains(t, text, "📝 *Registration:* `AB12CDE`\n")
+	assert.Contains(t, text, "📅 *Due Date:* `01.04.2025`\n")
+	assert.Contains(t, text, "✅ *London ULEZ:* `likely compliant`\n")
+	assert.Contains(t, text, "🔁 *Registration at Test:* `OLD1`\n")
+	assert.Contains(t, text, "  ❌ _Major_ `Brake pipe corroded`\n")
+}
+
+func TestFull_Plain(t *testing.T) {
+	text := Full(testResult(), Plain)
+
+	assert.Contains(t, text, "📝 Registration: AB12CDE\n")
+	assert.Contains(t, text, "📅 Due Date: 01.04.2025\n")
+	assert.Contains(t, text, "🔎 Mileage Check\n")
+	assert.Contains(t, text, "  ❌ Major Brake pipe corroded\n")
+	assert.NotContains(t, text, "`")
+	assert.NotContains(t, text, "*")
+	assert.NotContains(t, text, "_")
+}
+
+func TestCleanAirVerdict(t *testing.T) {
+	var sb strings.Builder
+	w := NewWriter(&sb, Markdown)
+
+	w.CleanAirVerdict(testResult())
+	assert.Equal(t, "🌿 *Clean air:* ✅ `likely compliant` in every zone _(estimate)_\n", sb.String())
+
+	diesel := testResult()
+	diesel.MOT.FuelType = "Diesel"
+	diesel.VES.EuroStatus = "EURO 5"
+	sb.Reset()
+	w.CleanAirVerdict(diesel)
+	assert.Equal(t, "🌿 *Clean air:* ❌ `likely non-compliant` for London ULEZ, CAZ class D _(estimate)_\n", sb.String())
+}
+
+func TestHistory_DVLAStatus(t *testing.T) {
+	result := &lookup.Result{
+		Registration: "AB12CDE",
+		VES:          &ves.Vehicle{MotStatus: "Valid", MotExpiryDate: ves.CustomTime{Time: time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	var sb strings.Builder
+	NewWriter(&sb, Plain).History(result)
+
+	assert.Contains(t, sb.String(), "⚠️ MOT history is unavailable right now\n")
+	assert.Contains(t, sb.String(), "🔧 MOT Status (DVLA): Valid\n")
+	assert.Contains(t, sb.String(), "📅 MOT Expiry (DVLA): 28.02.2025\n")
+}
+
+func TestTest_EscapesUpstreamText(t *testing.T) {
+	test := mot.MotTest{
+		CompletedDate:      mot.Date{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		TestResult:         "FAILED",
+		OdometerResultType: "NOT_PERMITTED",
+		Defects:            []mot.Defect{{Text: "Wiper blade `split`", Type: "NEW_TYPE"}},
+	}
+
+	var sb strings.Builder
+	NewWriter(&sb, Markdown).Test("AB12CDE", test)
+
+	assert.Contains(t, sb.String(), "📏 *Mileage:* NOT\\_PERMITTED\n")
+	assert.Contains(t, sb.String(), " NEW\\_TYPE `Wiper blade 'split'`\n")
+
+	sb.Reset()
+	NewWriter(&sb, Plain).Test("AB12CDE", test)
+	assert.Contains(t, sb.String(), "📏 Mileage: NOT_PERMITTED\n")
+}
+
+func TestCleanAir_EscapesReason(t *testing.T) {
+	result := testResult()
+	result.VES.TypeApproval = "L3E_A2"
+
+	var sb strings.Builder
+	NewWriter(&sb, Markdown).CleanAir(result)
+
+	assert.Contains(t, sb.String(), "✅ *CAZ class C:* `likely compliant`\n  L3E\\_A2 vehicles are not charged in this zone _(Bath, Bradford, Sheffield, Tyneside)_\n")
+}
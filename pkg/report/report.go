@@ -0,0 +1,438 @@
+// Package report builds a printable PDF report of a vehicle from its MOT
+// history and DVLA details.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/pdf"
+)
+
+// Page layout in points
+const (
+	margin       = 50.0
+	footerY      = 30.0
+	bottom       = 60.0
+	labelWidth   = 140.0
+	indent       = 15.0
+	lineSpacing  = 1.35
+	titleSize    = 20.0
+	headingSize  = 13.0
+	bodySize     = 10.0
+	footerSize   = 8.0
+	contentWidth = pdf.A4Width - 2*margin
+)
+
+// column is a column of a table
+type column struct {
+	title string
+	width float64
+}
+
+// writer lays out text from the top of the page down, starting a new page
+// when the current one is full
+type writer struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+// PDF returns a report of the vehicle with its details, tax, mileage and
+// full MOT history with defects
+func PDF(result *lookup.Result, generatedAt time.Time) []byte {
+	doc := pdf.New(fmt.Sprintf("Vehicle report %s", result.Registration))
+	doc.Author = "MOT Bot"
+	doc.CreatedAt = generatedAt
+
+	w := &writer{doc: doc}
+	w.newPage()
+
+	w.text(pdf.FontBold, titleSize, fmt.Sprintf("Vehicle Report: %s", result.Registration))
+	w.text(pdf.FontRegular, bodySize, fmt.Sprintf("Generated %s", generatedAt.UTC().Format("02.01.2006 15:04 UTC")))
+
+	writeAlerts(w, result)
+	writeVehicle(w, result)
+	writeTax(w, result)
+	writeMOTSummary(w, result)
+	writeMileage(w, result)
+	writeHistory(w, result)
+
+	writeFooters(doc, result, generatedAt)
+	return doc.Bytes()
+}
+
+// writeAlerts writes the warnings shown above everything else
+func writeAlerts(w *writer, result *lookup.Result) {
+	var alerts []string
+	if result.VES != nil && result.VES.MarkedForExport {
+		alerts = append(alerts, "Marked for export: the DVLA has been told this vehicle is leaving the UK.")
+	}
+	if result.MOT != nil && result.MOT.OutstandingRecall() {
+		alerts = append(alerts, "Outstanding safety recall: contact a dealer for this make to have it fixed free of charge.")
+	}
+	if result.MOT != nil && len(result.MOT.MotTests) == 0 && !result.MOT.MotTestDueDate.IsZero() {
+		alerts = append(alerts, fmt.Sprintf("First MOT due on %s.", result.MOT.MotTestDueDate))
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	w.heading("Alerts")
+	for _, alert := range alerts {
+		w.paragraph(margin, pdf.FontBold, alert)
+	}
+}
+
+// writeVehicle writes the vehicle details, preferring the MOT history and
+// filling in from the DVLA
+func writeVehicle(w *writer, result *lookup.Result) {
+	w.heading("Vehicle Details")
+
+	motVehicle, vesVehicle := result.MOT, result.VES
+	if motVehicle == nil && vesVehicle == nil {
+		w.paragraph(margin, pdf.FontRegular, "Vehicle details are unavailable right now.")
+		return
+	}
+
+	w.field("Registration", result.Registration)
+	if motVehicle != nil {
+		w.field("Make", motVehicle.Make)
+		w.field("Model", motVehicle.Model)
+		w.field("Colour", motVehicle.PrimaryColour)
+		w.field("Fuel type", motVehicle.FuelType)
+		if motVehicle.EngineSize != "" {
+			w.field("Engine size", motVehicle.EngineSize+" cc")
+		}
+		w.field("First used", motVehicle.FirstUsedDate.String())
+		w.field("Registered", motVehicle.RegistrationDate.String())
+		w.field("Manufactured", motVehicle.ManufactureDate.String())
+		w.field("Outstanding recall", motVehicle.HasOutstandingRecall)
+	}
+	if vesVehicle != nil {
+		if motVehicle == nil {
+			w.field("Make", vesVehicle.Make)
+			w.field("Colour", vesVehicle.Colour)
+			w.field("Fuel type", vesVehicle.FuelType)
+			w.field("First registered", vesVehicle.MonthOfFirstRegistration.String())
+		}
+		if vesVehicle.EngineCapacity > 0 && (motVehicle == nil || motVehicle.EngineSize == "") {
+			w.field("Engine size", fmt.Sprintf("%d cc", vesVehicle.EngineCapacity))
+		}
+		if vesVehicle.YearOfManufacture > 0 {
+			w.field("Year of manufacture", fmt.Sprintf("%d", vesVehicle.YearOfManufacture))
+		}
+		if vesVehicle.Co2Emissions > 0 {
+			w.field("CO2 emissions", fmt.Sprintf("%d g/km", vesVehicle.Co2Emissions))
+		}
+		w.field("Euro status", vesVehicle.EuroStatus)
+		w.field("Real driving emissions", vesVehicle.RealDrivingEmissions)
+		w.field("Type approval", vesVehicle.TypeApproval)
+		w.field("Wheelplan", vesVehicle.Wheelplan)
+		if vesVehicle.RevenueWeight > 0 {
+			w.field("Revenue weight", fmt.Sprintf("%d kg", vesVehicle.RevenueWeight))
+		}
+		w.field("Last V5C issued", vesVehicle.DateOfLastV5CIssued.String())
+	}
+}
+
+// writeTax writes the DVLA tax and MOT status
+func writeTax(w *writer, result *lookup.Result) {
+	w.heading("Tax")
+	if result.VES == nil {
+		w.paragraph(margin, pdf.FontRegular, "Tax information is unavailable right now.")
+		return
+	}
+
+	w.field("Tax status", result.VES.TaxStatus)
+	w.field("Tax due", result.VES.TaxDueDate.String())
+	w.field("Additional rate ends", result.VES.ArtEndDate.String())
+	w.field("MOT status (DVLA)", result.VES.MotStatus)
+	w.field("MOT expiry (DVLA)", result.VES.MotExpiryDate.String())
+}
+
+// writeMOTSummary writes the latest MOT and the verdicts drawn from the history
+func writeMOTSummary(w *writer, result *lookup.Result) {
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		return
+	}
+
+	w.heading("MOT Summary")
+	if test := result.MOT.LatestTest(); test != nil {
+		w.field("Last MOT", fmt.Sprintf("%s on %s", test.TestResult, test.CompletedDate))
+	}
+	if expiry, ok := result.MOT.LatestExpiryDate(); ok {
+		w.field("MOT expiry", expiry.Format("02.01.2006"))
+	}
+
+	r := mot.AnalyseReliability(result.MOT.MotTests)
+	w.field("Reliability", r.Verdict())
+	if rate, ok := r.FirstTimePassRate(); ok {
+		w.field("First-time pass rate", fmt.Sprintf("%.0f%% (%d of %d MOTs)", rate*100, r.FirstTimePasses, r.FirstAttempts))
+	}
+	if perYear, ok := r.FailuresPerYear(); ok {
+		w.field("Failures per year", fmt.Sprintf("%.1f", perYear))
+	}
+	for _, area := range r.Recurring {
+		w.field("Recurring problem", fmt.Sprintf("%s at %d MOTs", area.Area, area.MOTs))
+	}
+
+	for _, issue := range mot.FindKnownIssues(result.MOT.MotTests) {
+		if !issue.FailedOn.IsZero() {
+			w.field("Known issue", fmt.Sprintf("%s (advised %s, failed %s)",
+				issue.Text, issue.FirstSeen.Format("02.01.2006"), issue.FailedOn.Format("02.01.2006")))
+			continue
+		}
+		w.field("Known issue", fmt.Sprintf("%s (advised at %d MOTs since %s, still not fixed)",
+			issue.Text, issue.MOTs, issue.FirstSeen.Format("02.01.2006")))
+	}
+}
+
+// writeMileage writes a table of the odometer readings and the anomalies found in them
+func writeMileage(w *writer, result *lookup.Result) {
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		return
+	}
+
+	mileage := mot.AnalyseMileage(result.MOT.MotTests)
+	w.heading("Mileage")
+	w.field("Verdict", mileage.Verdict())
+	if len(mileage.Readings) == 0 {
+		return
+	}
+
+	columns := []column{{"Date", 90}, {"Odometer", 110}, {"Miles", 90}, {"Test", 90}}
+	rows := make([][]string, 0, len(mileage.Readings))
+	for _, reading := range mileage.Readings {
+		outcome := "Passed"
+		if reading.Failed {
+			outcome = "Failed"
+		}
+		rows = append(rows, []string{
+			reading.Date.Format("02.01.2006"),
+			fmt.Sprintf("%s %s", reading.Value, reading.Unit),
+			fmt.Sprintf("%d", reading.Miles),
+			outcome,
+		})
+	}
+	w.space(4)
+	w.table(columns, rows)
+
+	if len(mileage.Anomalies) > 0 {
+		w.space(6)
+		w.text(pdf.FontBold, bodySize, "Anomalies")
+		for _, anomaly := range mileage.Anomalies {
+			w.paragraph(margin+indent, pdf.FontRegular, fmt.Sprintf("%s: %s", anomaly.Date.Format("02.01.2006"), anomaly.Description))
+		}
+	}
+}
+
+// writeHistory writes every MOT test with its defects, newest first
+func writeHistory(w *writer, result *lookup.Result) {
+	w.heading("MOT History")
+	switch {
+	case result.NoMOTRecord():
+		w.paragraph(margin, pdf.FontRegular, "No MOT record found. New vehicles don't need an MOT until they are 3 years old.")
+		return
+	case result.MOT == nil:
+		w.paragraph(margin, pdf.FontRegular, "MOT history is unavailable right now.")
+		return
+	case len(result.MOT.MotTests) == 0:
+		w.paragraph(margin, pdf.FontRegular, "No MOT tests recorded yet.")
+		return
+	}
+
+	for i, test := range newestFirst(result.MOT.MotTests) {
+		if i > 0 {
+			w.space(8)
+		}
+		// Keep the title of a test with at least its first lines
+		w.ensure(4 * bodySize * lineSpacing)
+		w.text(pdf.FontBold, bodySize+1, fmt.Sprintf("%s  %s", test.CompletedDate, test.TestResult))
+
+		mileage := test.OdometerResultDescription()
+		if test.OdometerValue != "" && test.OdometerResultType == mot.OdometerRead {
+			mileage = fmt.Sprintf("%s %s", test.OdometerValue, test.OdometerUnit)
+		}
+		w.field("Mileage", mileage)
+		w.field("Expiry", test.ExpiryDate.String())
+		w.field("Test number", test.MotTestNumber)
+		if test.RegistrationAtTimeOfTest != "" && test.RegistrationAtTimeOfTest != result.Registration {
+			w.field("Registration at test", test.RegistrationAtTimeOfTest)
+		}
+		w.field("Location", test.Location)
+
+		if len(test.Defects) == 0 {
+			w.paragraph(margin+indent, pdf.FontRegular, "No defects recorded")
+			continue
+		}
+		defects := make([]mot.Defect, len(test.Defects))
+		copy(defects, test.Defects)
+		mot.SortDefects(defects)
+		for _, defect := range defects {
+			w.paragraph(margin+indent, pdf.FontRegular, fmt.Sprintf("[%s] %s", defect.Severity().Label(), defect.Text))
+		}
+	}
+}
+
+// writeFooters writes the source, generation time and page number at the bottom of every page
+func writeFooters(doc *pdf.Document, result *lookup.Result, generatedAt time.Time) {
+	pages := doc.Pages()
+	left := fmt.Sprintf("%s - generated %s from DVSA MOT history and DVLA vehicle data",
+		result.Registration, generatedAt.UTC().Format("02.01.2006 15:04 UTC"))
+	for i, page := range pages {
+		page.Line(margin, footerY+12, pdf.A4Width-margin, footerY+12, 0.5)
+		page.Text(margin, footerY, pdf.FontRegular, footerSize, left)
+
+		number := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		width := pdf.TextWidth(pdf.FontRegular, footerSize, number)
+		page.Text(pdf.A4Width-margin-width, footerY, pdf.FontRegular, footerSize, number)
+	}
+}
+
+// newestFirst returns a copy of the tests sorted by completion date, newest first
+func newestFirst(tests []mot.MotTest) []mot.MotTest {
+	sorted := make([]mot.MotTest, len(tests))
+	copy(sorted, tests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CompletedDate.After(sorted[j].CompletedDate.Time)
+	})
+	return sorted
+}
+
+// newPage starts a new page and moves to its top
+func (w *writer) newPage() {
+	w.page = w.doc.AddPage()
+	w.y = pdf.A4Height - margin
+}
+
+// ensure starts a new page unless height points fit on the current one
+func (w *writer) ensure(height float64) {
+	if w.y-height < bottom {
+		w.newPage()
+	}
+}
+
+// space moves down by the given number of points
+func (w *writer) space(height float64) {
+	w.y -= height
+}
+
+// text writes a single line at the left margin
+func (w *writer) text(font pdf.Font, size float64, text string) {
+	w.ensure(size * lineSpacing)
+	w.y -= size * lineSpacing
+	w.page.Text(margin, w.y, font, size, text)
+}
+
+// heading writes a section heading underlined across the page
+func (w *writer) heading(text string) {
+	// Keep the heading with at least a few lines of its section
+	w.ensure(headingSize*lineSpacing + 12 + 3*bodySize*lineSpacing)
+	w.space(12)
+	w.text(pdf.FontBold, headingSize, text)
+	w.page.Line(margin, w.y-4, pdf.A4Width-margin, w.y-4, 0.75)
+	w.space(6)
+}
+
+// paragraph writes text wrapped to the right margin, starting at x
+func (w *writer) paragraph(x float64, font pdf.Font, text string) {
+	for _, line := range wrap(font, bodySize, text, pdf.A4Width-margin-x) {
+		w.ensure(bodySize * lineSpacing)
+		w.y -= bodySize * lineSpacing
+		w.page.Text(x, w.y, font, bodySize, line)
+	}
+}
+
+// field writes a bold label with its value wrapped beside it. Empty values are skipped.
+func (w *writer) field(label, value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+
+	lines := wrap(pdf.FontRegular, bodySize, value, contentWidth-labelWidth)
+	for i, line := range lines {
+		w.ensure(bodySize * lineSpacing)
+		w.y -= bodySize * lineSpacing
+		if i == 0 {
+			w.page.Text(margin, w.y, pdf.FontBold, bodySize, label)
+		}
+		w.page.Text(margin+labelWidth, w.y, pdf.FontRegular, bodySize, line)
+	}
+}
+
+// table writes rows under a shaded header, repeating the header on every new page
+func (w *writer) table(columns []column, rows [][]string) {
+	rowHeight := bodySize * lineSpacing
+
+	header := func() {
+		w.ensure(2 * rowHeight)
+		w.y -= rowHeight
+		width := 0.0
+		for _, c := range columns {
+			width += c.width
+		}
+		w.page.FillRect(margin, w.y-3, width, rowHeight, 0.88)
+		x := margin + 3
+		for _, c := range columns {
+			w.page.Text(x, w.y, pdf.FontBold, bodySize, c.title)
+			x += c.width
+		}
+	}
+
+	header()
+	for _, row := range rows {
+		if w.y-rowHeight < bottom {
+			w.newPage()
+			header()
+		}
+		w.y -= rowHeight
+		x := margin + 3
+		for i, c := range columns {
+			if i < len(row) {
+				w.page.Text(x, w.y, pdf.FontRegular, bodySize, row[i])
+			}
+			x += c.width
+		}
+	}
+}
+
+// wrap splits text into lines no wider than width, breaking words that are
+// too long to fit on a line of their own
+func wrap(font pdf.Font, size float64, text string, width float64) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		for pdf.TextWidth(font, size, word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			n := len(runes) - 1
+			for n > 1 && pdf.TextWidth(font, size, string(runes[:n])) > width {
+				n--
+			}
+			lines = append(lines, string(runes[:n]))
+			word = string(runes[n:])
+		}
+
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if pdf.TextWidth(font, size, candidate) > width && line != "" {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/pdf"
+	"mot-bot/pkg/ves"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPDF(t *testing.T) {
+	var tests []mot.MotTest
+	for year := 2010; year <= 2024; year++ {
+		date, err := mot.ParseDate(fmt.Sprintf("%d-05-01", year))
+		assert.NoError(t, err)
+		tests = append(tests, mot.MotTest{
+			CompletedDate:      date,
+			TestResult:         "PASSED",
+			OdometerValue:      fmt.Sprintf("%d", (year-2009)*9000),
+			OdometerUnit:       "mi",
+			OdometerResultType: mot.OdometerRead,
+			MotTestNumber:      fmt.Sprintf("%012d", year),
+			Defects: []mot.Defect{
+				{Text: "Nearside Front Tyre worn close to legal limit/worn on edge (5.2.3 (e))", Type: mot.DefectAdvisory},
+				{Text: "Offside Rear Brake pipe slightly corroded (1.1.11 (c))", Type: mot.DefectAdvisory},
+				{Text: "Exhaust has a minor leak of exhaust gases (6.1.2 (a))", Type: mot.DefectMinor},
+			},
+		})
+	}
+
+	result := &lookup.Result{
+		Registration: "AB12CDE",
+		MOT: &mot.VehicleResponse{
+			Registration: "AB12CDE",
+			Make:         "FORD",
+			Model:        "FOCUS",
+			FuelType:     "Petrol",
+			MotTests:     tests,
+		},
+		VES: &ves.Vehicle{RegistrationNumber: "AB12CDE", TaxStatus: "Taxed", EuroStatus: "Euro 4"},
+	}
+
+	data := PDF(result, time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC))
+
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+	assert.Contains(t, string(data), "(Vehicle Report: AB12CDE) Tj")
+	assert.Contains(t, string(data), "(Generated 16.10.2026 12:30 UTC) Tj")
+	assert.Contains(t, string(data), "(Taxed) Tj")
+	assert.Contains(t, string(data), "(01.05.2024  PASSED) Tj")
+	assert.Contains(t, string(data), "([Minor] Exhaust has a minor leak of exhaust gases \\(6.1.2 \\(a\\)\\)) Tj")
+
+	pages := bytes.Count(data, []byte("/Type /Page /Parent"))
+	assert.Greater(t, pages, 1)
+	assert.Contains(t, string(data), fmt.Sprintf("(Page %d of %d) Tj", pages, pages))
+}
+
+func TestPDF_Unavailable(t *testing.T) {
+	data := PDF(&lookup.Result{Registration: "AB12CDE"}, time.Now())
+
+	assert.Contains(t, string(data), "(Vehicle details are unavailable right now.) Tj")
+	assert.Contains(t, string(data), "(MOT history is unavailable right now.) Tj")
+	assert.Contains(t, string(data), "(Page 1 of 1) Tj")
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap(pdf.FontRegular, 10, "the quick brown fox jumps over the lazy dog", 60)
+	assert.Greater(t, len(lines), 1)
+	for _, line := range lines {
+		assert.LessOrEqual(t, pdf.TextWidth(pdf.FontRegular, 10, line), 60.0)
+	}
+
+	lines = wrap(pdf.FontRegular, 10, "ABCDEFGHIJKLMNOPQRSTUVWXYZ", 50)
+	assert.Greater(t, len(lines), 1)
+	assert.Equal(t, []string{""}, wrap(pdf.FontRegular, 10, "", 50))
+}
@@ -2,14 +2,21 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"mot-bot/pkg/cache"
 	"mot-bot/pkg/db"
+	"mot-bot/pkg/lookup"
 	"mot-bot/pkg/mot"
+	"mot-bot/pkg/plate"
+	"mot-bot/pkg/render"
+	"mot-bot/pkg/upstream"
 	"mot-bot/pkg/ves"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -19,21 +26,56 @@ const (
 	maxMessageLength = 4096
 )
 
+// Config holds the settings of the bot
+type Config struct {
+	// AdminList is a space separated list of usernames or user IDs allowed to use admin commands
+	AdminList string
+	// ReminderDays is how many days before a due date reminders are sent
+	ReminderDays int
+	// Workers is how many updates are handled concurrently
+	Workers int
+	// RequestTimeout bounds the handling of a single update
+	RequestTimeout time.Duration
+	// BulkTimeout bounds the lookups of a file of registrations
+	BulkTimeout time.Duration
+	// CacheTTL is how long cached API responses are kept, 0 if there is no cache
+	CacheTTL time.Duration
+}
+
 type Bot struct {
-	bot       *tgbotapi.BotAPI
-	motClient mot.ClientInterface
-	vesClient ves.ClientInterface
-	logger    *db.Logger
-	adminList string
+	bot            *tgbotapi.BotAPI
+	motClient      mot.ClientInterface
+	vesClient      ves.ClientInterface
+	logger         *db.Logger
+	adminList      string
+	reminderDays   int
+	workers        int
+	requestTimeout time.Duration
+	bulkTimeout    time.Duration
+	cacheTTL       time.Duration
+
+	// Bulk checks run outside the update workers. bulkCtx ends when the bot
+	// shuts down, bulkSlots limits how many run at once and bulkJobs lets
+	// shutdown wait for them.
+	bulkCtx   context.Context
+	bulkSlots chan struct{}
+	bulkJobs  sync.WaitGroup
 }
 
-func NewBot(bot *tgbotapi.BotAPI, motClient mot.ClientInterface, vesClient ves.ClientInterface, logger *db.Logger, adminList string) *Bot {
+func NewBot(bot *tgbotapi.BotAPI, motClient mot.ClientInterface, vesClient ves.ClientInterface, logger *db.Logger, config Config) *Bot {
 	return &Bot{
-		bot:       bot,
-		motClient: motClient,
-		vesClient: vesClient,
-		logger:    logger,
-		adminList: adminList,
+		bot:            bot,
+		motClient:      motClient,
+		vesClient:      vesClient,
+		logger:         logger,
+		adminList:      config.AdminList,
+		reminderDays:   config.ReminderDays,
+		workers:        config.Workers,
+		requestTimeout: config.RequestTimeout,
+		bulkTimeout:    config.BulkTimeout,
+		cacheTTL:       config.CacheTTL,
+		bulkCtx:        context.Background(),
+		bulkSlots:      make(chan struct{}, maxBulkJobs),
 	}
 }
 
@@ -71,6 +113,12 @@ func (b *Bot) splitMessage(text string) []string {
 
 // sendMessage sends a message to a chat, splitting it into chunks if necessary
 func (b *Bot) sendMessage(chatID int64, text string) error {
+	return b.sendMessageWithMarkup(chatID, text, nil)
+}
+
+// sendMessageWithMarkup sends a message like sendMessage and attaches the
+// reply markup to the last chunk
+func (b *Bot) sendMessageWithMarkup(chatID int64, text string, markup interface{}) error {
 	chunks := b.splitMessage(text)
 	for i, chunk := range chunks {
 		msg := tgbotapi.NewMessage(chatID, chunk)
@@ -78,6 +126,9 @@ func (b *Bot) sendMessage(chatID int64, text string) error {
 		if i > 0 {
 			msg.Text = fmt.Sprintf("(Part %d/%d)\n%s", i+1, len(chunks), chunk)
 		}
+		if i == len(chunks)-1 && markup != nil {
+			msg.ReplyMarkup = markup
+		}
 		if _, err := b.bot.Send(msg); err != nil {
 			return fmt.Errorf("failed to send message part %d: %w", i+1, err)
 		}
@@ -85,92 +136,256 @@ func (b *Bot) sendMessage(chatID int64, text string) error {
 	return nil
 }
 
+// Start receives updates by long polling until ctx is cancelled
 func (b *Bot) Start(ctx context.Context) error {
+	// Telegram refuses long polling while a webhook is set
+	if _, err := b.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("Failed to delete webhook: %v", err)
+	}
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := b.bot.GetUpdatesChan(u)
+	// Stopping twice would panic, and run stops receiving on shutdown
+	stopReceiving := sync.OnceFunc(b.bot.StopReceivingUpdates)
+	defer stopReceiving()
+
+	return b.run(ctx, updates, stopReceiving)
+}
+
+// run dispatches updates from the channel to the workers until ctx is
+// cancelled. It then calls stopReceiving so no more updates are accepted,
+// hands the updates already received to the workers and waits until they,
+// the reminders and any bulk checks have finished.
+func (b *Bot) run(ctx context.Context, updates <-chan tgbotapi.Update, stopReceiving func()) error {
+	var reminders sync.WaitGroup
+	reminders.Add(1)
+	go func() {
+		defer reminders.Done()
+		b.runReminders(ctx)
+	}()
+	defer reminders.Wait()
+
+	// Bulk checks stop early on shutdown, and are waited for once the
+	// workers, which may still start one, are done
+	b.bulkCtx = ctx
+	defer b.bulkJobs.Wait()
+
+	// Handlers don't inherit the cancellation of ctx, so in-flight work can
+	// finish on shutdown, but each one is bounded by the request timeout
+	workCtx := context.WithoutCancel(ctx)
+	d := newDispatcher(b.workers, func(update tgbotapi.Update) {
+		reqCtx, cancel := context.WithTimeout(workCtx, b.requestTimeout)
+		defer cancel()
+		b.handleUpdate(reqCtx, update)
+	})
+	defer d.stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			log.Println("Stopping bot, waiting for in-flight updates...")
+			drainUpdates(workCtx, d, updates, stopReceiving)
 			return ctx.Err()
-		case update := <-updates:
-			if update.Message == nil {
-				continue
+		case update, ok := <-updates:
+			if !ok {
+				return nil
 			}
+			d.dispatch(ctx, update)
+		}
+	}
+}
 
-			if !update.Message.IsCommand() {
-				// Handle registration number
-				registration := strings.TrimSpace(update.Message.Text)
-				if err := b.handleRegistration(ctx, update.Message.Chat.ID, registration); err != nil {
-					log.Printf("Error handling registration: %v", err)
-					if err := b.sendMessage(update.Message.Chat.ID, "Sorry, I couldn't process that registration number. Please try again."); err != nil {
-						log.Printf("Error sending error message: %v", err)
-					}
-				}
-				continue
-			}
+// drainUpdates calls stopReceiving and hands every update received until
+// it returns to the dispatcher. Updates are read while stopReceiving runs, so
+// senders blocked on a full channel can finish.
+func drainUpdates(ctx context.Context, d *dispatcher, updates <-chan tgbotapi.Update, stopReceiving func()) {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		stopReceiving()
+	}()
 
-			// Handle commands
-			switch update.Message.Command() {
-			case "start":
-				if err := b.sendMessage(update.Message.Chat.ID, "Welcome to the MOT Checker Bot! Send me a UK vehicle registration number to check its MOT history."); err != nil {
-					log.Printf("Error sending start message: %v", err)
-				}
-			case "help":
-				if err := b.sendMessage(update.Message.Chat.ID, "Simply send me a UK vehicle registration number to check its MOT history."); err != nil {
-					log.Printf("Error sending help message: %v", err)
-				}
-			case "stats":
-				if err := b.handleStats(update.Message); err != nil {
-					log.Printf("Error handling stats command: %v", err)
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				<-stopped
+				return
+			}
+			d.dispatch(ctx, update)
+		case <-stopped:
+			// Nothing is added any more, so hand over what is left
+			for {
+				select {
+				case update, ok := <-updates:
+					if !ok {
+						return
+					}
+					d.dispatch(ctx, update)
+				default:
+					return
 				}
 			}
 		}
 	}
 }
 
-func (b *Bot) handleRegistration(ctx context.Context, chatID int64, registration string) error {
-	// Get data from both APIs concurrently
-	motChan := make(chan *mot.VehicleResponse)
-	vesChan := make(chan *ves.Vehicle)
-	errChan := make(chan error, 2)
+// handleUpdate handles a single update received by polling or webhook
+func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	if update.InlineQuery != nil {
+		if err := b.handleInlineQuery(ctx, update.InlineQuery); err != nil {
+			log.Printf("Error handling inline query: %v", err)
+		}
+		return
+	}
 
-	go func() {
-		vehicle, err := b.motClient.GetVehicleByRegistration(ctx, registration)
-		if err != nil {
-			errChan <- fmt.Errorf("MOT API error: %w", err)
-			return
+	if update.ChosenInlineResult != nil {
+		b.handleChosenInlineResult(update.ChosenInlineResult)
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		if err := b.handleCallback(ctx, update.CallbackQuery); err != nil {
+			log.Printf("Error handling callback query: %v", err)
 		}
-		motChan <- vehicle
-	}()
+		return
+	}
 
-	go func() {
-		vehicle, err := b.vesClient.GetVehicleByRegistration(ctx, registration)
+	if update.Message == nil {
+		return
+	}
+
+	if update.Message.Document != nil {
+		if err := b.handleBulkUpload(ctx, update.Message); err != nil {
+			log.Printf("Error handling bulk upload: %v", err)
+		}
+		return
+	}
+
+	if !update.Message.IsCommand() {
+		// Handle registration number
+		registration, _, err := plate.Parse(update.Message.Text)
 		if err != nil {
-			errChan <- fmt.Errorf("VES API error: %w", err)
+			if err := b.sendMessage(update.Message.Chat.ID, invalidPlateMessage(update.Message.Text)); err != nil {
+				log.Printf("Error sending invalid registration message: %v", err)
+			}
 			return
 		}
-		vesChan <- vehicle
-	}()
-
-	// Wait for both responses
-	var motVehicle *mot.VehicleResponse
-	var vesVehicle *ves.Vehicle
-	var err error
+		if err := b.handleRegistration(ctx, update.Message.Chat.ID, registration); err != nil {
+			log.Printf("Error handling registration: %v", err)
+			if err := b.sendMessage(update.Message.Chat.ID, lookupErrorMessage(err)); err != nil {
+				log.Printf("Error sending error message: %v", err)
+			}
+		}
+		return
+	}
 
-	for i := 0; i < 2; i++ {
-		select {
-		case motVehicle = <-motChan:
-		case vesVehicle = <-vesChan:
-		case err = <-errChan:
-			return err
+	// Handle commands
+	switch update.Message.Command() {
+	case "start":
+		if err := b.sendMessage(update.Message.Chat.ID, "Welcome to the MOT Checker Bot! Send me a UK vehicle registration number to check its MOT history."); err != nil {
+			log.Printf("Error sending start message: %v", err)
+		}
+	case "help":
+		if err := b.sendMessage(update.Message.Chat.ID, "Simply send me a UK vehicle registration number to check its MOT history. "+
+			"Use the buttons under the summary to page through the MOT history, defects, tax and mileage.\n\n"+
+			"Tap \"🔔 Remind me\" under a result to get a message before the MOT or tax is due, and use /reminders to list or cancel your reminders.\n\n"+
+			"Keep your vehicles in a garage with `/add <reg> [name]` and `/remove <reg>`, then send /garage to check them all at once.\n\n"+
+			"Send `/mileage <reg>` or tap \"📈 Mileage chart\" for a chart of the odometer readings.\n\n"+
+			"Compare up to three vehicles side by side with `/compare <reg1> <reg2> [reg3]`.\n\n"+
+			"Send `/report <reg>` for a printable PDF report with the full MOT history, or `/export <reg> csv|json` for the raw data as a file.\n\n"+
+			"To check many vehicles at once, send a `.txt` or `.csv` file with one registration per line and I'll reply with a CSV of their MOT and tax status.\n\n"+
+			"Results are cached for a while, use `/refresh <reg>` to fetch the latest data.\n\n"+
+			"You can also type my username followed by a registration in any chat to share a vehicle card there."); err != nil {
+			log.Printf("Error sending help message: %v", err)
+		}
+	case "stats":
+		if err := b.handleStats(update.Message); err != nil {
+			log.Printf("Error handling stats command: %v", err)
+		}
+	case "reminders":
+		if err := b.handleReminders(update.Message); err != nil {
+			log.Printf("Error handling reminders command: %v", err)
+		}
+	case "refresh":
+		if err := b.handleRefresh(ctx, update.Message); err != nil {
+			log.Printf("Error handling refresh command: %v", err)
+		}
+	case "garage":
+		if err := b.handleGarage(ctx, update.Message); err != nil {
+			log.Printf("Error handling garage command: %v", err)
+		}
+	case "mileage":
+		if err := b.handleMileage(ctx, update.Message); err != nil {
+			log.Printf("Error handling mileage command: %v", err)
+		}
+	case "compare":
+		if err := b.handleCompare(ctx, update.Message); err != nil {
+			log.Printf("Error handling compare command: %v", err)
+		}
+	case "report":
+		if err := b.handleReport(ctx, update.Message); err != nil {
+			log.Printf("Error handling report command: %v", err)
+		}
+	case "export":
+		if err := b.handleExport(ctx, update.Message); err != nil {
+			log.Printf("Error handling export command: %v", err)
 		}
+	case "export_logs":
+		if err := b.handleExportLogs(update.Message); err != nil {
+			log.Printf("Error handling export logs command: %v", err)
+		}
+	case "add":
+		if err := b.handleAdd(update.Message); err != nil {
+			log.Printf("Error handling add command: %v", err)
+		}
+	case "remove":
+		if err := b.handleRemove(update.Message); err != nil {
+			log.Printf("Error handling remove command: %v", err)
+		}
+	}
+}
+
+// handleCallback dispatches inline button presses
+func (b *Bot) handleCallback(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	switch {
+	case strings.HasPrefix(query.Data, remindCallbackPrefix):
+		return b.handleRemind(ctx, query, strings.TrimPrefix(query.Data, remindCallbackPrefix))
+	case strings.HasPrefix(query.Data, cancelReminderCallbackPrefix):
+		return b.handleCancelReminder(query, strings.TrimPrefix(query.Data, cancelReminderCallbackPrefix))
+	case strings.HasPrefix(query.Data, chartCallbackPrefix):
+		return b.handleChartCallback(ctx, query, strings.TrimPrefix(query.Data, chartCallbackPrefix))
+	case strings.HasPrefix(query.Data, viewCallbackPrefix):
+		return b.handleViewCallback(ctx, query)
+	default:
+		return b.answerCallback(query.ID, "")
+	}
+}
+
+// answerCallback acknowledges a callback query, optionally showing a notification
+func (b *Bot) answerCallback(queryID, text string) error {
+	if _, err := b.bot.Request(tgbotapi.NewCallback(queryID, text)); err != nil {
+		return fmt.Errorf("failed to answer callback query: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) handleRegistration(ctx context.Context, chatID int64, registration string) error {
+	result := lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+	if err := result.Err(); err != nil {
+		return err
+	}
+	if result.MOTErr != nil && !result.NoMOTRecord() {
+		log.Printf("Partial result for %s: %v", registration, result.MOTErr)
+	}
+	if result.VESErr != nil {
+		log.Printf("Partial result for %s: %v", registration, result.VESErr)
 	}
 
 	// Format combined response
-	response := formatCombinedResponse(motVehicle, vesVehicle)
+	response := render.Full(result, render.Markdown)
 
 	// Get user information
 	chatConfig := tgbotapi.ChatInfoConfig{
@@ -207,7 +422,29 @@ func (b *Bot) handleRegistration(ctx context.Context, chatID int64, registration
 		log.Printf("Failed to log request: %v", err)
 	}
 
-	return b.sendMessage(chatID, response)
+	text, markup := renderView(result, viewSummary, 0)
+	return b.sendMessageWithMarkup(chatID, text, markup)
+}
+
+// handleRefresh looks up a vehicle bypassing the response cache: /refresh <reg>
+func (b *Bot) handleRefresh(ctx context.Context, message *tgbotapi.Message) error {
+	args := message.CommandArguments()
+	if strings.TrimSpace(args) == "" {
+		return b.sendMessage(message.Chat.ID, "Usage: `/refresh <reg>`")
+	}
+
+	registration, _, err := plate.Parse(args)
+	if err != nil {
+		return b.sendMessage(message.Chat.ID, invalidPlateMessage(args))
+	}
+
+	if err := b.handleRegistration(cache.WithRefresh(ctx), message.Chat.ID, registration); err != nil {
+		if sendErr := b.sendMessage(message.Chat.ID, lookupErrorMessage(err)); sendErr != nil {
+			log.Printf("Error sending error message: %v", sendErr)
+		}
+		return err
+	}
+	return nil
 }
 
 func (b *Bot) handleStats(message *tgbotapi.Message) error {
@@ -232,6 +469,81 @@ func (b *Bot) handleStats(message *tgbotapi.Message) error {
 	return b.sendMessage(message.Chat.ID, response)
 }
 
+// lookupArgs looks up the registration given as command arguments. If there
+// is none, it isn't valid or the lookup fails, the user is told so and ok is
+// false.
+func (b *Bot) lookupArgs(ctx context.Context, chatID int64, args []string, usage string) (*lookup.Result, bool) {
+	if len(args) == 0 {
+		if err := b.sendMessage(chatID, usage); err != nil {
+			log.Printf("Error sending usage message: %v", err)
+		}
+		return nil, false
+	}
+
+	registration, _, err := plate.Parse(strings.Join(args, ""))
+	if err != nil {
+		if err := b.sendMessage(chatID, invalidPlateMessage(strings.Join(args, " "))); err != nil {
+			log.Printf("Error sending invalid registration message: %v", err)
+		}
+		return nil, false
+	}
+
+	result := lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+	if err := result.Err(); err != nil {
+		log.Printf("Error looking up %s: %v", registration, err)
+		if err := b.sendMessage(chatID, lookupErrorMessage(err)); err != nil {
+			log.Printf("Error sending error message: %v", err)
+		}
+		return nil, false
+	}
+	return result, true
+}
+
+// lookupErrorMessage returns a user-facing explanation of a failed vehicle lookup
+func lookupErrorMessage(err error) string {
+	service := "The DVSA/DVLA service"
+	var apiErr *upstream.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Service {
+		case "MOT":
+			service = "The DVSA MOT history service"
+		case "VES":
+			service = "The DVLA vehicle enquiry service"
+		}
+	}
+
+	switch {
+	case errors.Is(err, upstream.ErrNotFound):
+		return "I couldn't find a vehicle with that registration number. Please check it and try again."
+	case errors.Is(err, upstream.ErrBadRequest):
+		return "That registration number was rejected as invalid. Please check it and try again."
+	case errors.Is(err, upstream.ErrUnauthorised):
+		return "The bot can't access the vehicle data services right now. Please let the bot admin know."
+	case errors.Is(err, upstream.ErrRateLimited):
+		return "There have been too many lookups in a short time. Please try again in a minute."
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The lookup took too long. Please try again."
+	case errors.Is(err, context.Canceled):
+		return "The bot is restarting. Please try again in a moment."
+	case errors.Is(err, upstream.ErrUnavailable):
+		return fmt.Sprintf("%s isn't responding right now. Please try again later.", service)
+	default:
+		return "Sorry, I couldn't process that registration number. Please try again."
+	}
+}
+
+// invalidPlateMessage explains which registration formats are accepted
+func invalidPlateMessage(input string) string {
+	input = strings.ReplaceAll(strings.TrimSpace(input), "`", "")
+	if input == "" || len(input) > 20 {
+		input = "That"
+	} else {
+		input = fmt.Sprintf("`%s`", input)
+	}
+	return fmt.Sprintf("%s doesn't look like a UK registration number. "+
+		"Send it in one of these formats: `AB12 CDE`, `A123 BCD`, `ABC 123D`, `ABC 1234` or `AIZ 1234`.", input)
+}
+
 // isAdmin checks if the given user (by ID or username) is in the admin list
 func (b *Bot) isAdmin(userID int64, username string) bool {
 	if b.adminList == "" {
@@ -251,75 +563,3 @@ func (b *Bot) isAdmin(userID int64, username string) bool {
 
 	return false
 }
-
-func formatCombinedResponse(motVehicle *mot.VehicleResponse, vesVehicle *ves.Vehicle) string {
-	var sb strings.Builder
-
-	// Basic vehicle info
-	sb.WriteString(fmt.Sprintf("🚗 *Vehicle Information*\n\n"))
-	sb.WriteString(fmt.Sprintf("📝 *Registration:* `%s`\n", motVehicle.Registration))
-	sb.WriteString(fmt.Sprintf("🏭 *Make:* `%s`\n", motVehicle.Make))
-	sb.WriteString(fmt.Sprintf("🚘 *Model:* `%s`\n", motVehicle.Model))
-	sb.WriteString(fmt.Sprintf("📅 *First Registered:* `%s`\n", motVehicle.FirstUsedDate))
-	sb.WriteString(fmt.Sprintf("⛽ *Fuel Type:* `%s`\n", motVehicle.FuelType))
-	sb.WriteString(fmt.Sprintf("🎨 *Colour:* `%s`\n", motVehicle.PrimaryColour))
-	sb.WriteString(fmt.Sprintf("🛞 *Wheelplan:* `%s`\n", vesVehicle.Wheelplan))
-	sb.WriteString(fmt.Sprintf("🌍 *Euro Status:* `%s`\n", vesVehicle.EuroStatus))
-	sb.WriteString(fmt.Sprintf("📄 *Last V5C Issued:* `%s`\n", vesVehicle.DateOfLastV5CIssued.Format("02.01.2006")))
-
-	// Tax information
-	sb.WriteString("\n💰 *Tax Information*\n\n")
-	sb.WriteString(fmt.Sprintf("📊 *Status:* `%s`\n", vesVehicle.TaxStatus))
-	if !vesVehicle.TaxDueDate.IsZero() {
-		sb.WriteString(fmt.Sprintf("📅 *Due Date:* `%s`\n", vesVehicle.TaxDueDate.Format("02.01.2006")))
-	}
-
-	// MOT history
-	sb.WriteString("\n🔧 *MOT History*\n\n")
-	for _, test := range motVehicle.MotTests {
-		// Format date to DD.MM.YYYY
-		testDate := test.CompletedDate
-		if len(testDate) >= 10 {
-			// Parse the date from YYYY-MM-DD format
-			parsedDate, err := time.Parse("2006-01-02", testDate[:10])
-			if err == nil {
-				testDate = parsedDate.Format("02.01.2006")
-			}
-		}
-		sb.WriteString(fmt.Sprintf("📅 *Test Date:* `%s`\n", testDate))
-
-		// Set appropriate emoji for test result
-		var resultEmoji string
-		if strings.ToUpper(test.TestResult) == "FAILED" {
-			resultEmoji = "❌"
-		} else {
-			resultEmoji = "✅"
-		}
-		sb.WriteString(fmt.Sprintf("%s *Result:* `%s`\n", resultEmoji, test.TestResult))
-
-		if test.OdometerValue != "" {
-			sb.WriteString(fmt.Sprintf("📏 *Mileage:* `%s %s`\n", test.OdometerValue, test.OdometerUnit))
-		}
-		if len(test.Defects) > 0 {
-			sb.WriteString("⚠️ *Defects:*\n")
-			for _, defect := range test.Defects {
-				// Convert defect type to emoji
-				var defectEmoji string
-				switch defect.Type {
-				case "FAIL":
-					defectEmoji = "❌"
-				case "ADVISORY":
-					defectEmoji = "⚠️"
-				case "USER ENTERED":
-					defectEmoji = "📝"
-				default:
-					defectEmoji = "ℹ️"
-				}
-				sb.WriteString(fmt.Sprintf("  %s `%s`\n", defectEmoji, defect.Text))
-			}
-		}
-		sb.WriteString("\n")
-	}
-
-	return sb.String()
-}
@@ -0,0 +1,391 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mot-bot/pkg/export"
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/plate"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxBulkPlates limits how many registrations a single file may contain
+	maxBulkPlates = 500
+
+	// maxBulkFileSize limits the size of an uploaded file in bytes
+	maxBulkFileSize = 1 << 20
+
+	// bulkConcurrency limits how many vehicles of a file are looked up at once
+	bulkConcurrency = 5
+
+	// bulkProgressInterval is how often the progress message is updated
+	bulkProgressInterval = 3 * time.Second
+
+	// maxBulkJobs limits how many files are checked at once
+	maxBulkJobs = 2
+)
+
+// bulkEntry is a line of an uploaded file with the registration read from it
+type bulkEntry struct {
+	input        string
+	registration string
+	err          error
+}
+
+// handleBulkUpload acknowledges an uploaded .txt or .csv file and checks it
+// in the background, so the chat's other messages aren't held up meanwhile
+func (b *Bot) handleBulkUpload(ctx context.Context, message *tgbotapi.Message) error {
+	doc := message.Document
+	ext := strings.ToLower(path.Ext(doc.FileName))
+	if ext != ".txt" && ext != ".csv" {
+		// Other files shared in a group aren't meant for the bot
+		if !b.isAddressedToBot(message) {
+			return nil
+		}
+		return b.sendMessage(message.Chat.ID, "Send a `.txt` or `.csv` file with one registration per line to check many vehicles at once.")
+	}
+	if doc.FileSize > maxBulkFileSize {
+		return b.sendMessage(message.Chat.ID, fmt.Sprintf("The file is too large, the limit is %d KB.", maxBulkFileSize>>10))
+	}
+	if b.bulkCtx.Err() != nil {
+		return b.sendMessage(message.Chat.ID, "The bot is restarting. Please send the file again in a moment.")
+	}
+
+	status, err := b.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "⏳ Got the file, checking it shortly..."))
+	if err != nil {
+		return fmt.Errorf("failed to send progress message: %w", err)
+	}
+
+	b.bulkJobs.Add(1)
+	go func() {
+		defer b.bulkJobs.Done()
+
+		select {
+		case b.bulkSlots <- struct{}{}:
+			defer func() { <-b.bulkSlots }()
+		case <-b.bulkCtx.Done():
+			b.editBulkStatus(status, "The bot is restarting. Please send the file again in a moment.")
+			return
+		}
+
+		// A bulk check takes longer than a single lookup, so it gets its own
+		// deadline, but it still stops early when the bot shuts down
+		ctx, cancel := context.WithTimeout(b.bulkCtx, b.bulkTimeout)
+		defer cancel()
+
+		if err := b.checkBulkFile(ctx, message, status, ext == ".csv"); err != nil {
+			log.Printf("Error handling bulk upload: %v", err)
+		}
+	}()
+	return nil
+}
+
+// isAddressedToBot reports whether a file is meant for the bot: it was sent
+// in a private chat, or its caption mentions the bot or is a command
+func (b *Bot) isAddressedToBot(message *tgbotapi.Message) bool {
+	if message.Chat.IsPrivate() {
+		return true
+	}
+	caption := strings.ToLower(message.Caption)
+	if strings.HasPrefix(caption, "/") {
+		return true
+	}
+	return b.bot.Self.UserName != "" && strings.Contains(caption, "@"+strings.ToLower(b.bot.Self.UserName))
+}
+
+// checkBulkFile looks up every registration in the uploaded file and replies
+// with a CSV of their MOT and tax status. If ctx ends first, the CSV holds
+// the vehicles checked so far.
+func (b *Bot) checkBulkFile(ctx context.Context, message *tgbotapi.Message, status tgbotapi.Message, isCSV bool) error {
+	data, err := b.downloadFile(ctx, message.Document.FileID)
+	if err != nil {
+		b.editBulkStatus(status, "Sorry, I couldn't download the file. Please try again.")
+		return err
+	}
+
+	entries, err := parseBulkFile(data, isCSV)
+	if err != nil {
+		b.editBulkStatus(status, fmt.Sprintf("Sorry, I couldn't read the file: %v", err))
+		return nil
+	}
+	if len(entries) == 0 {
+		b.editBulkStatus(status, "The file doesn't contain any registrations.")
+		return nil
+	}
+	if len(entries) > maxBulkPlates {
+		b.editBulkStatus(status, fmt.Sprintf("The file has %d lines, the limit is %d registrations per file.", len(entries), maxBulkPlates))
+		return nil
+	}
+
+	b.editBulkStatus(status, bulkProgressText(0, len(entries)))
+	var done atomic.Int64
+	stopProgress := b.reportBulkProgress(status, &done, len(entries))
+	summaries := b.bulkLookup(ctx, message.From, entries, &done)
+	stopProgress()
+
+	var buf bytes.Buffer
+	if err := export.SummaryCSV(&buf, summaries); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, s := range summaries {
+		if s.Error != "" {
+			failed++
+		}
+	}
+	var text string
+	if ctx.Err() != nil {
+		text = fmt.Sprintf("⚠️ Stopped after checking %d of %d vehicles, %s", done.Load(), len(summaries), bulkStopReason(ctx.Err()))
+	} else {
+		text = fmt.Sprintf("✅ Checked %d vehicles", len(summaries))
+		if failed > 0 {
+			text += fmt.Sprintf(", %d with errors", failed)
+		}
+	}
+	b.editBulkStatus(status, text)
+
+	name := fmt.Sprintf("mot-check-%s.csv", time.Now().Format("2006-01-02"))
+	return b.sendFile(message.Chat.ID, name, buf.Bytes(), fmt.Sprintf("📋 *MOT and tax status* of %d vehicles", len(summaries)))
+}
+
+// bulkStopReason explains why a bulk check ended early
+func bulkStopReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "the check took too long. Send a shorter file for the rest."
+	}
+	return "the bot is restarting. Send the file again for the rest."
+}
+
+// bulkLookup looks up the entries on bulkConcurrency workers, counting the
+// finished ones in done, and returns their status in input order. Repeated
+// registrations are only looked up once. Entries that weren't looked up
+// before ctx ended are marked as not checked.
+//
+// Every lookup is written to the request log under the user who sent the
+// file, like a single lookup would be.
+func (b *Bot) bulkLookup(ctx context.Context, user *tgbotapi.User, entries []bulkEntry, done *atomic.Int64) []export.Summary {
+	var registrations []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.err == nil && !seen[entry.registration] {
+			seen[entry.registration] = true
+			registrations = append(registrations, entry.registration)
+		}
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]*lookup.Result)
+	queue := make(chan string)
+	var wg sync.WaitGroup
+
+	for range min(bulkConcurrency, len(registrations)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for registration := range queue {
+				if ctx.Err() != nil {
+					// Leave the rest as not checked
+					continue
+				}
+				result := lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+				b.logBulkLookup(user, result)
+
+				mu.Lock()
+				results[registration] = result
+				mu.Unlock()
+				done.Add(1)
+			}
+		}()
+	}
+
+feed:
+	for _, registration := range registrations {
+		select {
+		case queue <- registration:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	summaries := make([]export.Summary, len(entries))
+	for i, entry := range entries {
+		switch result := results[entry.registration]; {
+		case entry.err != nil:
+			summaries[i] = export.Summary{Input: entry.input, Error: entry.err.Error()}
+		case result == nil:
+			summaries[i] = export.Summary{Input: entry.input, Registration: entry.registration, Error: "not checked"}
+		default:
+			summaries[i] = export.NewSummary(entry.input, result)
+		}
+	}
+	return summaries
+}
+
+// logBulkLookup writes a lookup of a bulk check to the request log
+func (b *Bot) logBulkLookup(user *tgbotapi.User, result *lookup.Result) {
+	s := export.NewSummary(result.Registration, result)
+	response := fmt.Sprintf("Bulk check: MOT %s, tax %s", s.MOTResult, s.TaxStatus)
+	if s.Error != "" {
+		response = "Bulk check error: " + s.Error
+	}
+
+	var userID int64
+	username := "unknown"
+	if user != nil {
+		userID = user.ID
+		username = inlineUsername(user)
+	}
+	if err := b.logger.LogRequest(userID, username, result.Registration, response); err != nil {
+		log.Printf("Failed to log request: %v", err)
+	}
+}
+
+// reportBulkProgress updates the progress message until the returned function is called
+func (b *Bot) reportBulkProgress(status tgbotapi.Message, done *atomic.Int64, total int) func() {
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(bulkProgressInterval)
+		defer ticker.Stop()
+
+		var last int64
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if n := done.Load(); n != last {
+					last = n
+					b.editBulkStatus(status, bulkProgressText(int(n), total))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		wg.Wait()
+	}
+}
+
+// editBulkStatus replaces the text of the progress message
+func (b *Bot) editBulkStatus(status tgbotapi.Message, text string) {
+	edit := tgbotapi.NewEditMessageText(status.Chat.ID, status.MessageID, text)
+	if _, err := b.bot.Send(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("Error updating bulk progress: %v", err)
+	}
+}
+
+// bulkProgressText returns the text of the progress message
+func bulkProgressText(done, total int) string {
+	return fmt.Sprintf("⏳ Checking %d vehicles... %d/%d done", total, done, total)
+}
+
+// downloadFile downloads a file sent to the bot
+func (b *Bot) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	fileURL, err := b.bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The URL contains the bot token, so keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBulkFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxBulkFileSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxBulkFileSize)
+	}
+	return data, nil
+}
+
+// parseBulkFile reads the registrations of an uploaded file, one per line,
+// or from the first column of a CSV file. Blank lines are skipped, as is a
+// header row that doesn't hold a registration.
+func parseBulkFile(data []byte, isCSV bool) ([]bulkEntry, error) {
+	// Spreadsheet programs often start UTF-8 files with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var lines []string
+	if isCSV {
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		for _, record := range records {
+			if len(record) > 0 {
+				lines = append(lines, record[0])
+			}
+		}
+	} else {
+		lines = strings.Split(string(data), "\n")
+	}
+
+	var entries []bulkEntry
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		registration, _, err := plate.Parse(line)
+		if err != nil && len(entries) == 0 && isBulkHeader(line) {
+			continue
+		}
+		entries = append(entries, bulkEntry{input: line, registration: registration, err: err})
+	}
+	return entries, nil
+}
+
+// isBulkHeader reports whether the first line of a file is a column title
+func isBulkHeader(line string) bool {
+	line = strings.ToLower(line)
+	for _, title := range []string{"reg", "plate", "vrm", "vehicle"} {
+		if strings.Contains(line, title) {
+			return true
+		}
+	}
+	return false
+}
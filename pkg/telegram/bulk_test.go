@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBulkFile_Text(t *testing.T) {
+	entries, err := parseBulkFile([]byte("\xef\xbb\xbfAB12 CDE\r\n\n  xy99zzz \nnot a plate\n"), false)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "AB12 CDE", entries[0].input)
+	assert.Equal(t, "AB12CDE", entries[0].registration)
+	assert.NoError(t, entries[0].err)
+	assert.Equal(t, "XY99ZZZ", entries[1].registration)
+	assert.Equal(t, "not a plate", entries[2].input)
+	assert.Error(t, entries[2].err)
+}
+
+func TestParseBulkFile_CSV(t *testing.T) {
+	entries, err := parseBulkFile([]byte("Registration,Driver\nAB12CDE,Alex\n\"XY99 ZZZ\",Sam\n,\n"), true)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "AB12CDE", entries[0].registration)
+	assert.Equal(t, "XY99ZZZ", entries[1].registration)
+}
+
+func TestBulkLookup(t *testing.T) {
+	motClient := &fakeMOTClient{block: make(chan struct{}), started: make(chan string, 100)}
+	b, _ := newTestBot(t, motClient, &fakeVESClient{}, Config{})
+
+	var entries []bulkEntry
+	for i := range 12 {
+		registration := fmt.Sprintf("AB%02dCDE", i%8)
+		entries = append(entries, bulkEntry{input: fmt.Sprintf("line %d", i), registration: registration})
+	}
+	entries = append(entries, bulkEntry{input: "not a plate", err: errors.New("invalid registration")})
+
+	go func() {
+		// Let every worker start a lookup before any of them finishes
+		for range bulkConcurrency {
+			<-motClient.started
+		}
+		close(motClient.block)
+	}()
+
+	var done atomic.Int64
+	summaries := b.bulkLookup(context.Background(), &tgbotapi.User{ID: 1, UserName: "alex"}, entries, &done)
+
+	assert.Len(t, motClient.registrations(), 8, "repeated registrations are looked up once")
+	assert.Equal(t, int64(8), done.Load())
+	assert.Equal(t, bulkConcurrency, motClient.maxActive)
+
+	require.Len(t, summaries, len(entries))
+	for i, entry := range entries[:12] {
+		assert.Equal(t, entry.input, summaries[i].Input)
+		assert.Equal(t, entry.registration, summaries[i].Registration)
+		assert.Equal(t, "FORD", summaries[i].Make)
+		assert.Empty(t, summaries[i].Error)
+	}
+	assert.Equal(t, "invalid registration", summaries[12].Error)
+
+	logs, err := b.logger.GetRequestLogs(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, logs, 8)
+}
+
+func TestBulkLookup_Cancelled(t *testing.T) {
+	motClient := &fakeMOTClient{block: make(chan struct{}), started: make(chan string, 100)}
+	b, _ := newTestBot(t, motClient, &fakeVESClient{}, Config{})
+
+	var entries []bulkEntry
+	for i := range 8 {
+		registration := fmt.Sprintf("AB%02dCDE", i)
+		entries = append(entries, bulkEntry{input: registration, registration: registration})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for range bulkConcurrency {
+			<-motClient.started
+		}
+		cancel()
+	}()
+
+	var done atomic.Int64
+	summaries := b.bulkLookup(ctx, &tgbotapi.User{ID: 1}, entries, &done)
+
+	require.Len(t, summaries, len(entries))
+	assert.Len(t, motClient.registrations(), bulkConcurrency)
+	notChecked := 0
+	for i, s := range summaries {
+		assert.Equal(t, entries[i].registration, s.Registration)
+		assert.NotEmpty(t, s.Error)
+		if s.Error == "not checked" {
+			notChecked++
+		}
+	}
+	assert.Equal(t, len(entries)-bulkConcurrency, notChecked)
+}
+
+func TestHandleBulkUpload_OtherFilesInGroups(t *testing.T) {
+	b, fake := newTestBot(t, nil, nil, Config{})
+	upload := func(chatType, caption string) {
+		message := &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: 10, Type: chatType},
+			Document: &tgbotapi.Document{FileName: "photo.pdf"},
+			Caption:  caption,
+		}
+		require.NoError(t, b.handleBulkUpload(context.Background(), message))
+	}
+
+	upload("group", "")
+	upload("supergroup", "holiday plans")
+	assert.Empty(t, fake.calls("sendMessage"))
+
+	upload("private", "")
+	upload("group", "@TestBot check these")
+	upload("group", "/check")
+	assert.Len(t, fake.calls("sendMessage"), 3)
+}
@@ -0,0 +1,118 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"mot-bot/pkg/chart"
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/render"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const chartCallbackPrefix = "chart:"
+
+// chartButton returns the button under a lookup reply that sends the mileage chart
+func chartButton(registration string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("📈 Mileage chart", chartCallbackPrefix+registration)
+}
+
+// handleMileage sends the mileage chart of a vehicle: /mileage <reg>
+func (b *Bot) handleMileage(ctx context.Context, message *tgbotapi.Message) error {
+	result, ok := b.lookupArgs(ctx, message.Chat.ID, strings.Fields(message.CommandArguments()),
+		"Usage: `/mileage <reg>`, for example `/mileage AB12CDE`")
+	if !ok {
+		return nil
+	}
+
+	if err := b.sendMileageChart(message.Chat.ID, result); err != nil {
+		if sendErr := b.sendMessage(message.Chat.ID, lookupErrorMessage(err)); sendErr != nil {
+			log.Printf("Error sending error message: %v", sendErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// handleChartCallback sends the mileage chart when its button is pressed
+func (b *Bot) handleChartCallback(ctx context.Context, query *tgbotapi.CallbackQuery, registration string) error {
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "Charts can only be sent in a chat with the bot.")
+	}
+
+	if err := b.answerCallback(query.ID, ""); err != nil {
+		log.Printf("Error answering callback query: %v", err)
+	}
+
+	result := lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+	if err := b.sendMileageChart(query.Message.Chat.ID, result); err != nil {
+		if sendErr := b.sendMessage(query.Message.Chat.ID, lookupErrorMessage(err)); sendErr != nil {
+			log.Printf("Error sending error message: %v", sendErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// sendMileageChart sends a chart of the odometer readings of a looked up vehicle
+func (b *Bot) sendMileageChart(chatID int64, result *lookup.Result) error {
+	registration := result.Registration
+	if result.MOT == nil {
+		if err := result.Err(); err != nil {
+			return err
+		}
+		if result.NoMOTRecord() {
+			return b.sendMessage(chatID, fmt.Sprintf("`%s` has no MOT record yet, so there are no odometer readings to chart.", registration))
+		}
+		return result.MOTErr
+	}
+
+	mileage := mot.AnalyseMileage(result.MOT.MotTests)
+	if len(mileage.Readings) == 0 {
+		return b.sendMessage(chatID, fmt.Sprintf("`%s` has no odometer readings to chart.", registration))
+	}
+
+	data, err := mileageChart(registration, &mileage).PNG()
+	if err != nil {
+		return fmt.Errorf("failed to draw mileage chart: %w", err)
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("mileage-%s.png", registration),
+		Bytes: data,
+	})
+	photo.Caption = fmt.Sprintf("📈 *Mileage of* `%s`\n%s `%s`", registration, render.MileageEmoji(&mileage), mileage.Verdict())
+	photo.ParseMode = "Markdown"
+	if _, err := b.bot.Send(photo); err != nil {
+		return fmt.Errorf("failed to send mileage chart: %w", err)
+	}
+	return nil
+}
+
+// mileageChart builds the chart of odometer readings, marking failed tests
+// and the readings where an anomaly was found
+func mileageChart(registration string, mileage *mot.MileageReport) chart.LineChart {
+	anomalies := make(map[int64]bool)
+	for _, a := range mileage.Anomalies {
+		anomalies[a.Date.Unix()] = true
+	}
+
+	points := make([]chart.Point, 0, len(mileage.Readings))
+	for _, r := range mileage.Readings {
+		points = append(points, chart.Point{
+			Time:    r.Date,
+			Value:   float64(r.Miles),
+			Failed:  r.Failed,
+			Anomaly: anomalies[r.Date.Unix()],
+		})
+	}
+
+	return chart.LineChart{
+		Title:  fmt.Sprintf("%s mileage", registration),
+		Points: points,
+	}
+}
@@ -0,0 +1,185 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/plate"
+	"mot-bot/pkg/upstream"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	minCompareVehicles = 2
+	maxCompareVehicles = 3
+)
+
+// compareRow is a single metric of the comparison table
+type compareRow struct {
+	label string
+	value func(result *lookup.Result, now time.Time) string
+}
+
+// compareRows are the metrics shown by /compare, in order
+var compareRows = []compareRow{
+	{"Age", compareAge},
+	{"Fuel", func(r *lookup.Result, _ time.Time) string {
+		switch {
+		case r.MOT != nil && r.MOT.FuelType != "":
+			return r.MOT.FuelType
+		case r.VES != nil && r.VES.FuelType != "":
+			return r.VES.FuelType
+		default:
+			return "-"
+		}
+	}},
+	{"Engine", func(r *lookup.Result, _ time.Time) string {
+		switch {
+		case r.MOT != nil && r.MOT.EngineSize != "":
+			return r.MOT.EngineSize + "cc"
+		case r.VES != nil && r.VES.EngineCapacity > 0:
+			return fmt.Sprintf("%dcc", r.VES.EngineCapacity)
+		default:
+			return "-"
+		}
+	}},
+	{"Euro", func(r *lookup.Result, _ time.Time) string {
+		if r.VES == nil || r.VES.EuroStatus == "" {
+			return "-"
+		}
+		return r.VES.EuroStatus
+	}},
+	{"Mileage", func(r *lookup.Result, _ time.Time) string {
+		if r.MOT == nil {
+			return "-"
+		}
+		report := mot.AnalyseMileage(r.MOT.MotTests)
+		if len(report.Readings) == 0 {
+			return "-"
+		}
+		last := report.Readings[len(report.Readings)-1]
+		return fmt.Sprintf("%d mi", last.Miles)
+	}},
+	{"Pass rate", func(r *lookup.Result, _ time.Time) string {
+		if r.MOT == nil {
+			return "-"
+		}
+		rate, ok := r.MOT.PassRate()
+		if !ok {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%% of %d", rate*100, len(r.MOT.MotTests))
+	}},
+	{"Advisories", func(r *lookup.Result, _ time.Time) string {
+		if r.MOT == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", r.MOT.AdvisoryCount())
+	}},
+	{"Dangerous", func(r *lookup.Result, _ time.Time) string {
+		if r.MOT == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", r.MOT.DangerousCount())
+	}},
+}
+
+// handleCompare shows several vehicles side by side: /compare <reg1> <reg2> [reg3]
+func (b *Bot) handleCompare(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < minCompareVehicles || len(args) > maxCompareVehicles {
+		return b.sendMessage(message.Chat.ID, "Usage: `/compare <reg1> <reg2> [reg3]`, for example `/compare AB12CDE CD34EFG`")
+	}
+
+	registrations := make([]string, len(args))
+	for i, arg := range args {
+		registration, _, err := plate.Parse(arg)
+		if err != nil {
+			return b.sendMessage(message.Chat.ID, invalidPlateMessage(arg))
+		}
+		registrations[i] = registration
+	}
+
+	results := make([]*lookup.Result, len(registrations))
+	var wg sync.WaitGroup
+	for i, registration := range registrations {
+		wg.Add(1)
+		go func(i int, registration string) {
+			defer wg.Done()
+			results[i] = lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+		}(i, registration)
+	}
+	wg.Wait()
+
+	return b.sendMessage(message.Chat.ID, formatComparison(results, time.Now()))
+}
+
+// formatComparison renders the vehicles as a monospace table with one column per vehicle
+func formatComparison(results []*lookup.Result, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("⚖️ *Vehicle Comparison*\n\n")
+
+	for _, r := range results {
+		switch err := r.Err(); {
+		case err == nil && r.MOT != nil:
+			sb.WriteString(fmt.Sprintf("🚗 `%s` %s %s\n", r.Registration, r.MOT.Make, r.MOT.Model))
+		case err == nil && r.VES != nil:
+			sb.WriteString(fmt.Sprintf("🚗 `%s` %s\n", r.Registration, r.VES.Make))
+		case err == nil:
+			sb.WriteString(fmt.Sprintf("🚗 `%s`\n", r.Registration))
+		case errors.Is(err, upstream.ErrNotFound):
+			sb.WriteString(fmt.Sprintf("❓ `%s` not found\n", r.Registration))
+		default:
+			sb.WriteString(fmt.Sprintf("❌ `%s` couldn't be looked up\n", r.Registration))
+		}
+	}
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 1, ' ', 0)
+
+	header := []string{""}
+	for _, r := range results {
+		header = append(header, r.Registration)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, row := range compareRows {
+		cells := []string{row.label}
+		for _, r := range results {
+			value := "-"
+			if r.Err() == nil {
+				value = row.value(r, now)
+			}
+			cells = append(cells, value)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+
+	sb.WriteString(fmt.Sprintf("\n```\n%s```", table.String()))
+	return sb.String()
+}
+
+// compareAge returns the age of the vehicle in years since it was first
+// used, or since its first registration if DVSA doesn't know the first use
+func compareAge(r *lookup.Result, now time.Time) string {
+	var firstUsed time.Time
+	if r.MOT != nil {
+		firstUsed, _ = r.MOT.FirstUsed()
+	}
+	if firstUsed.IsZero() && r.VES != nil {
+		firstUsed = r.VES.MonthOfFirstRegistration.Time
+	}
+	if firstUsed.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f yrs", now.Sub(firstUsed).Hours()/24/365.25)
+}
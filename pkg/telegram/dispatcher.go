@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// workerQueueSize is how many updates may wait for each worker
+const workerQueueSize = 100
+
+// dispatcher handles updates on a fixed number of workers. Updates from the
+// same chat always go to the same worker, so they are handled in order.
+type dispatcher struct {
+	queues []chan tgbotapi.Update
+	wg     sync.WaitGroup
+}
+
+func newDispatcher(workers int, handle func(tgbotapi.Update)) *dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+
+	d := &dispatcher{queues: make([]chan tgbotapi.Update, workers)}
+	for i := range d.queues {
+		queue := make(chan tgbotapi.Update, workerQueueSize)
+		d.queues[i] = queue
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for update := range queue {
+				handle(update)
+			}
+		}()
+	}
+	return d
+}
+
+// dispatch queues an update on the worker of its chat. It blocks while that
+// worker's queue is full and gives up if ctx is done.
+func (d *dispatcher) dispatch(ctx context.Context, update tgbotapi.Update) bool {
+	queue := d.queues[uint64(updateChatID(update))%uint64(len(d.queues))]
+	select {
+	case queue <- update:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// stop waits until every queued update has been handled
+func (d *dispatcher) stop() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
+
+// updateChatID returns the chat an update belongs to, falling back to the
+// sender for updates that are not tied to a chat
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From.ID
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,39 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_KeepsChatOrder(t *testing.T) {
+	var mu sync.Mutex
+	handled := map[int64][]int{}
+
+	d := newDispatcher(4, func(update tgbotapi.Update) {
+		mu.Lock()
+		defer mu.Unlock()
+		chatID := update.Message.Chat.ID
+		handled[chatID] = append(handled[chatID], update.UpdateID)
+	})
+
+	for i := 0; i < 50; i++ {
+		update := tgbotapi.Update{
+			UpdateID: i,
+			Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: int64(i % 5)}},
+		}
+		assert.True(t, d.dispatch(context.Background(), update))
+	}
+	d.stop()
+
+	for chatID := int64(0); chatID < 5; chatID++ {
+		var want []int
+		for i := int(chatID); i < 50; i += 5 {
+			want = append(want, i)
+		}
+		assert.Equal(t, want, handled[chatID])
+	}
+}
@@ -0,0 +1,188 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mot-bot/pkg/db"
+	"mot-bot/pkg/export"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// exportDateLayout is the date format accepted by /export_logs
+	exportDateLayout = "2006-01-02"
+	// defaultExportDays is how many days /export_logs covers without a first day
+	defaultExportDays = 30
+	// maxExportSize is the largest file the bot can send to Telegram
+	maxExportSize = 50 << 20
+)
+
+// errExportTooLarge stops an export that won't fit into a Telegram file
+var errExportTooLarge = errors.New("export is too large")
+
+// handleExport sends the raw data of a vehicle as a file: /export <reg> [csv|json]
+func (b *Bot) handleExport(ctx context.Context, message *tgbotapi.Message) error {
+	const usage = "Usage: `/export <reg> csv|json`, for example `/export AB12CDE csv`"
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.sendMessage(message.Chat.ID, usage)
+	}
+
+	format := export.FormatCSV
+	if last := strings.ToLower(args[len(args)-1]); last == export.FormatCSV || last == export.FormatJSON {
+		format = last
+		args = args[:len(args)-1]
+	}
+	result, ok := b.lookupArgs(ctx, message.Chat.ID, args, usage)
+	if !ok {
+		return nil
+	}
+	registration := result.Registration
+
+	var buf bytes.Buffer
+	var err error
+	if format == export.FormatJSON {
+		err = export.JSON(&buf, result, time.Now())
+	} else {
+		err = export.CSV(&buf, result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", registration, err)
+	}
+
+	return b.sendFile(message.Chat.ID, fmt.Sprintf("%s.%s", registration, format), buf.Bytes(),
+		fmt.Sprintf("📦 *Export of* `%s`", registration))
+}
+
+// handleExportLogs sends the request log as CSV to an admin: /export_logs [from] [to]
+func (b *Bot) handleExportLogs(message *tgbotapi.Message) error {
+	if !b.isAdmin(message.From.ID, message.From.UserName) {
+		return b.sendMessage(message.Chat.ID, "Sorry, this command is only available to administrators.")
+	}
+
+	from, to, err := parseExportRange(strings.Fields(message.CommandArguments()), time.Now().UTC())
+	if err != nil {
+		return b.sendMessage(message.Chat.ID, "Usage: `/export_logs [from] [to]` with dates as `YYYY-MM-DD`, for example `/export_logs 2024-01-01 2024-01-31`")
+	}
+
+	var buf bytes.Buffer
+	logs, err := newRequestLogsCSV(&buf, maxExportSize)
+	if err != nil {
+		return err
+	}
+	err = b.logger.EachRequestLog(from, to, logs.write)
+	if errors.Is(err, errExportTooLarge) {
+		return b.sendMessage(message.Chat.ID, fmt.Sprintf("The request log of that period is larger than Telegram's %d MB file limit. Please export a shorter period.", maxExportSize>>20))
+	}
+	if err != nil {
+		return err
+	}
+	if err := logs.close(); err != nil {
+		return err
+	}
+	if logs.rows == 0 {
+		return b.sendMessage(message.Chat.ID, "No requests were logged in that period.")
+	}
+
+	name := fmt.Sprintf("request-logs-%s-to-%s.csv", from.Format(exportDateLayout), to.AddDate(0, 0, -1).Format(exportDateLayout))
+	return b.sendFile(message.Chat.ID, name, buf.Bytes(), fmt.Sprintf("📦 `%d` logged requests", logs.rows))
+}
+
+// parseExportRange parses the optional first and last day of /export_logs.
+// Without a first day the range covers the last defaultExportDays days, and
+// without a last day it ends today. The returned end is exclusive.
+func parseExportRange(args []string, now time.Time) (time.Time, time.Time, error) {
+	if len(args) > 2 {
+		return time.Time{}, time.Time{}, errors.New("too many arguments")
+	}
+
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -defaultExportDays)
+	if len(args) > 0 {
+		t, err := time.Parse(exportDateLayout, args[0])
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date: %w", err)
+		}
+		from = t
+	}
+	if len(args) > 1 {
+		t, err := time.Parse(exportDateLayout, args[1])
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date: %w", err)
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, errors.New("from date is after to date")
+	}
+	return from, to, nil
+}
+
+// requestLogsCSV writes request log rows as CSV. The text columns come from
+// users, so they are guarded against formula injection.
+type requestLogsCSV struct {
+	buf   *bytes.Buffer
+	w     *csv.Writer
+	limit int
+	rows  int
+}
+
+// newRequestLogsCSV writes the CSV header to buf and returns a writer for the
+// rows that fails with errExportTooLarge once buf grows past limit bytes
+func newRequestLogsCSV(buf *bytes.Buffer, limit int) (*requestLogsCSV, error) {
+	c := &requestLogsCSV{buf: buf, w: csv.NewWriter(buf), limit: limit}
+	if err := c.w.Write([]string{"id", "timestamp", "user_id", "username", "car_plate", "response"}); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	return c, nil
+}
+
+// write appends a row
+func (c *requestLogsCSV) write(r db.RequestLog) error {
+	record := []string{
+		strconv.FormatInt(r.ID, 10),
+		r.Timestamp.UTC().Format(time.RFC3339),
+		strconv.FormatInt(r.UserID, 10),
+		export.CSVText(r.Username),
+		export.CSVText(r.CarPlate),
+		export.CSVText(r.Response),
+	}
+	if err := c.w.Write(record); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+	c.w.Flush()
+	if c.buf.Len() > c.limit {
+		return errExportTooLarge
+	}
+	c.rows++
+	return nil
+}
+
+// close flushes the rows written so far
+func (c *requestLogsCSV) close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// sendFile sends data as a document with a Markdown caption
+func (b *Bot) sendFile(chatID int64, name string, data []byte, caption string) error {
+	document := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	document.Caption = caption
+	document.ParseMode = "Markdown"
+	if _, err := b.bot.Send(document); err != nil {
+		return fmt.Errorf("failed to send %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportRange(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+
+	from, to, err := parseExportRange(nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 9, 17, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), to)
+
+	from, to, err = parseExportRange([]string{"2024-01-01"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), to)
+
+	from, to, err = parseExportRange([]string{"2024-01-01", "2024-01-31"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = parseExportRange([]string{"01.01.2024"}, now)
+	assert.Error(t, err)
+	_, _, err = parseExportRange([]string{"2024-02-01", "2024-01-01"}, now)
+	assert.Error(t, err)
+	_, _, err = parseExportRange([]string{"2024-01-01", "2024-01-02", "2024-01-03"}, now)
+	assert.Error(t, err)
+}
+
+func TestWriteRequestLogsCSV_FormulaInjection(t *testing.T) {
+	logs := []db.RequestLog{{
+		ID:        1,
+		Timestamp: time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC),
+		UserID:    -100123,
+		Username:  "=HYPERLINK(\"http://example.com\")",
+		CarPlate:  "AB12CDE",
+		Response:  "@SUM(1+1)",
+	}}
+
+	var buf bytes.Buffer
+	w, err := newRequestLogsCSV(&buf, maxExportSize)
+	require.NoError(t, err)
+	for _, r := range logs {
+		require.NoError(t, w.write(r))
+	}
+	require.NoError(t, w.close())
+
+	assert.Equal(t, "id,timestamp,user_id,username,car_plate,response\n"+
+		"1,2026-10-16T15:30:00Z,-100123,\"'=HYPERLINK(\"\"http://example.com\"\")\",AB12CDE,'@SUM(1+1)\n", buf.String())
+}
+
+func TestRequestLogsCSV_SizeLimit(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newRequestLogsCSV(&buf, 100)
+	require.NoError(t, err)
+
+	r := db.RequestLog{ID: 1, Timestamp: time.Now(), UserID: 7, Username: "alex", CarPlate: "AB12CDE", Response: "ok"}
+	require.NoError(t, w.write(r))
+	assert.ErrorIs(t, w.write(r), errExportTooLarge)
+	assert.Equal(t, 1, w.rows)
+}
@@ -0,0 +1,175 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/db"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/ves"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// sentRequest is a call the bot made to the fake Telegram Bot API
+type sentRequest struct {
+	method string
+	params map[string]string
+}
+
+// fakeTelegram records the calls made to the Telegram Bot API and answers
+// every one of them with a message, unless the method is set to fail
+type fakeTelegram struct {
+	mu       sync.Mutex
+	requests []sentRequest
+	nextID   int
+	failing  map[string]bool
+}
+
+// fail makes every following call of a method return an error
+func (f *fakeTelegram) fail(method string, failing bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failing == nil {
+		f.failing = make(map[string]bool)
+	}
+	f.failing[method] = failing
+}
+
+// calls returns the recorded calls of a method
+func (f *fakeTelegram) calls(method string) []sentRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []sentRequest
+	for _, r := range f.requests {
+		if r.method == method {
+			calls = append(calls, r)
+		}
+	}
+	return calls
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		_ = r.ParseMultipartForm(10 << 20)
+	} else {
+		_ = r.ParseForm()
+	}
+	params := make(map[string]string)
+	for key, values := range r.Form {
+		params[key] = values[0]
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, sentRequest{method: method, params: params})
+	f.nextID++
+	id := f.nextID
+	failing := f.failing[method]
+	f.mu.Unlock()
+
+	if failing {
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error_code": 500, "description": "Internal Server Error"})
+		return
+	}
+
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	var result any = map[string]any{
+		"message_id": id,
+		"date":       time.Now().Unix(),
+		"chat":       map[string]any{"id": chatID},
+	}
+	if method == "getMe" {
+		result = map[string]any{"id": 1, "is_bot": true, "username": "testbot"}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// newTestBot returns a bot that talks to a fake Telegram Bot API and stores
+// its data in a temporary database
+func newTestBot(t *testing.T, motClient mot.ClientInterface, vesClient ves.ClientInterface, config Config) (*Bot, *fakeTelegram) {
+	t.Helper()
+
+	fake := &fakeTelegram{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	api, err := tgbotapi.NewBotAPIWithClient("test-token", server.URL+"/bot%s/%s", server.Client())
+	require.NoError(t, err)
+
+	logger, err := db.NewLogger(fmt.Sprintf("%s/test.db", t.TempDir()))
+	require.NoError(t, err)
+	t.Cleanup(func() { logger.Close() })
+
+	if config.RequestTimeout == 0 {
+		config.RequestTimeout = 5 * time.Second
+	}
+	if config.Workers == 0 {
+		config.Workers = 2
+	}
+	return NewBot(api, motClient, vesClient, logger, config), fake
+}
+
+// fakeMOTClient records the registrations it was asked for. If block is set,
+// each lookup waits until block is closed or the context is done.
+type fakeMOTClient struct {
+	block   chan struct{}
+	started chan string
+
+	mu        sync.Mutex
+	requested []string
+	active    int
+	maxActive int
+}
+
+func (c *fakeMOTClient) GetVehicleByRegistration(ctx context.Context, registration string) (*mot.VehicleResponse, error) {
+	c.mu.Lock()
+	c.requested = append(c.requested, registration)
+	c.active++
+	c.maxActive = max(c.maxActive, c.active)
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.active--
+		c.mu.Unlock()
+	}()
+
+	if c.started != nil {
+		c.started <- registration
+	}
+	if c.block != nil {
+		select {
+		case <-c.block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &mot.VehicleResponse{Registration: registration, Make: "FORD", Model: "FOCUS"}, nil
+}
+
+// registrations returns the registrations looked up so far
+func (c *fakeMOTClient) registrations() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.requested...)
+}
+
+// fakeVESClient returns a taxed vehicle for every registration
+type fakeVESClient struct{}
+
+func (c *fakeVESClient) GetVehicleByRegistration(ctx context.Context, registration string) (*ves.Vehicle, error) {
+	return &ves.Vehicle{RegistrationNumber: registration, TaxStatus: "Taxed"}, nil
+}
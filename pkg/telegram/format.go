@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/render"
+)
+
+// markdown returns a writer of lookup sections in Telegram Markdown
+func markdown(sb *strings.Builder) *render.Writer {
+	return render.NewWriter(sb, render.Markdown)
+}
+
+// formatSummary renders the summary card shown for a lookup, with the
+// details available through the view buttons
+func formatSummary(result *lookup.Result) string {
+	var sb strings.Builder
+	w := markdown(&sb)
+
+	w.Alerts(result)
+	w.Reliability(result)
+	w.Vehicle(result)
+	w.KnownIssues(result)
+
+	sb.WriteString("\n📋 *Summary*\n\n")
+	switch {
+	case result.NoMOTRecord():
+		sb.WriteString("🔧 *MOT:* `no MOT record yet`\n")
+	case result.MOT == nil && result.VES != nil && result.VES.MotStatus != "":
+		// The DVLA keeps the MOT status too, so show that while the MOT history is unavailable
+		line := fmt.Sprintf("🔧 *MOT (DVLA):* `%s`", result.VES.MotStatus)
+		if !result.VES.MotExpiryDate.IsZero() {
+			line += fmt.Sprintf(", expires `%s`", result.VES.MotExpiryDate)
+		}
+		sb.WriteString(line + "\n")
+	case result.MOT == nil:
+		sb.WriteString("🔧 *MOT:* _unavailable right now_\n")
+	case result.MOT.LatestTest() == nil && !result.MOT.MotTestDueDate.IsZero():
+		sb.WriteString(fmt.Sprintf("🔧 *MOT:* `no tests yet`, first MOT due `%s`\n", result.MOT.MotTestDueDate))
+	case result.MOT.LatestTest() == nil:
+		sb.WriteString("🔧 *MOT:* `no tests recorded yet`\n")
+	default:
+		test := result.MOT.LatestTest()
+		resultEmoji := "✅"
+		if strings.ToUpper(test.TestResult) == "FAILED" {
+			resultEmoji = "❌"
+		}
+		sb.WriteString(fmt.Sprintf("🔧 *Last MOT:* %s `%s` on `%s`\n", resultEmoji, test.TestResult, test.CompletedDate))
+		if expiry, ok := result.MOT.LatestExpiryDate(); ok {
+			sb.WriteString(fmt.Sprintf("📅 *MOT Expiry:* `%s`\n", expiry.Format("02.01.2006")))
+		}
+	}
+
+	if result.VES != nil {
+		tax := fmt.Sprintf("`%s`", result.VES.TaxStatus)
+		if !result.VES.TaxDueDate.IsZero() {
+			tax += fmt.Sprintf(", due `%s`", result.VES.TaxDueDate)
+		}
+		sb.WriteString(fmt.Sprintf("💰 *Tax:* %s\n", tax))
+	} else {
+		sb.WriteString("💰 *Tax:* _unavailable right now_\n")
+	}
+
+	if result.MOT != nil && len(result.MOT.MotTests) > 0 {
+		mileage := mot.AnalyseMileage(result.MOT.MotTests)
+		sb.WriteString(fmt.Sprintf("🔎 *Mileage:* %s `%s`\n", render.MileageEmoji(&mileage), mileage.Verdict()))
+
+		withDefects := 0
+		for _, test := range result.MOT.MotTests {
+			if len(test.Defects) > 0 {
+				withDefects++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("📋 `%d` MOT tests, `%d` with defects\n", len(result.MOT.MotTests), withDefects))
+
+		systems := mot.AnalyseSystems(result.MOT.MotTests)
+		if len(systems.Systems) > 0 {
+			sb.WriteString("\n🛠 *Defects by System*\n\n")
+			w.SystemCounts(&systems)
+		}
+	}
+
+	w.CleanAirVerdict(result)
+
+	sb.WriteString("\n")
+	w.FetchedAt(result)
+
+	return sb.String()
+}
@@ -0,0 +1,179 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"mot-bot/pkg/db"
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/plate"
+	"mot-bot/pkg/upstream"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	maxGarageSize = 20
+
+	// garageConcurrency limits how many vehicles are looked up at once for /garage
+	garageConcurrency = 5
+
+	maxGarageNameLength = 16
+)
+
+// garageStatus is the looked up state of a single garage vehicle
+type garageStatus struct {
+	vehicle db.GarageVehicle
+	result  *lookup.Result
+}
+
+// handleAdd saves a vehicle to the user's garage: /add <reg> [name]
+func (b *Bot) handleAdd(message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.sendMessage(message.Chat.ID, "Usage: `/add <reg> [name]`, for example `/add AB12CDE Van 3`")
+	}
+
+	registration, _, err := plate.Parse(args[0])
+	if err != nil {
+		return b.sendMessage(message.Chat.ID, invalidPlateMessage(args[0]))
+	}
+	name := strings.ReplaceAll(strings.Join(args[1:], " "), "`", "")
+	if len([]rune(name)) > maxGarageNameLength {
+		name = string([]rune(name)[:maxGarageNameLength])
+	}
+
+	vehicles, err := b.logger.GetGarage(message.From.ID)
+	if err != nil {
+		return err
+	}
+
+	exists := false
+	for _, v := range vehicles {
+		if v.CarPlate == registration {
+			exists = true
+			break
+		}
+	}
+	if !exists && len(vehicles) >= maxGarageSize {
+		return b.sendMessage(message.Chat.ID, fmt.Sprintf("Your garage is full (%d vehicles). Use `/remove <reg>` to make room.", maxGarageSize))
+	}
+
+	if err := b.logger.AddGarageVehicle(message.From.ID, registration, name); err != nil {
+		return err
+	}
+
+	if exists {
+		return b.sendMessage(message.Chat.ID, fmt.Sprintf("✏️ Updated `%s` in your garage.", registration))
+	}
+	return b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Added `%s` to your garage. Send /garage to see its status.", registration))
+}
+
+// handleRemove removes a vehicle from the user's garage: /remove <reg>
+func (b *Bot) handleRemove(message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.sendMessage(message.Chat.ID, "Usage: `/remove <reg>`")
+	}
+
+	registration := plate.Normalise(args[0])
+	removed, err := b.logger.RemoveGarageVehicle(message.From.ID, registration)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		return b.sendMessage(message.Chat.ID, fmt.Sprintf("`%s` is not in your garage.", registration))
+	}
+	return b.sendMessage(message.Chat.ID, fmt.Sprintf("🗑 Removed `%s` from your garage.", registration))
+}
+
+// handleGarage shows the status of every vehicle in the user's garage
+func (b *Bot) handleGarage(ctx context.Context, message *tgbotapi.Message) error {
+	vehicles, err := b.logger.GetGarage(message.From.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(vehicles) == 0 {
+		return b.sendMessage(message.Chat.ID, "Your garage is empty. Use `/add <reg> [name]` to save a vehicle.")
+	}
+
+	statuses := make([]garageStatus, len(vehicles))
+	sem := make(chan struct{}, garageConcurrency)
+	var wg sync.WaitGroup
+	for i, v := range vehicles {
+		wg.Add(1)
+		go func(i int, v db.GarageVehicle) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			statuses[i] = garageStatus{vehicle: v, result: lookup.Fetch(ctx, b.motClient, b.vesClient, v.CarPlate)}
+		}(i, v)
+	}
+	wg.Wait()
+
+	return b.sendMessage(message.Chat.ID, formatGarage(statuses))
+}
+
+// formatGarage renders the garage as a compact monospace table
+func formatGarage(statuses []garageStatus) string {
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "Vehicle\tMOT\tExpiry\tTax")
+
+	expired := false
+	for _, s := range statuses {
+		label := s.vehicle.CarPlate
+		if s.vehicle.Name != "" {
+			label = fmt.Sprintf("%s %s", s.vehicle.Name, s.vehicle.CarPlate)
+		}
+
+		if err := s.result.Err(); err != nil {
+			status := "error"
+			if errors.Is(err, upstream.ErrNotFound) {
+				status = "not found"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", label, status, "-", "-")
+			continue
+		}
+
+		motResult, motExpiry, taxStatus := "-", "-", "error"
+		switch {
+		case s.result.NoMOTRecord():
+			motResult = "none"
+		case s.result.MOT == nil:
+			motResult = "error"
+		}
+		if s.result.MOT != nil {
+			if test := s.result.MOT.LatestTest(); test != nil {
+				motResult = test.TestResult
+			}
+			if expiry, ok := s.result.MOT.MOTDueDate(); ok {
+				motExpiry = expiry.Format("02.01.06")
+				if expiry.Before(time.Now()) {
+					motExpiry += "!"
+					expired = true
+				}
+			}
+		}
+		if s.result.VES != nil {
+			taxStatus = s.result.VES.TaxStatus
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", label, motResult, motExpiry, taxStatus)
+	}
+	w.Flush()
+
+	result := fmt.Sprintf("🏠 *Your Garage*\n\n```\n%s```", table.String())
+	if expired {
+		result += "\n`!` MOT expired"
+	}
+	return result
+}
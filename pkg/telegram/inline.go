@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+	"mot-bot/pkg/plate"
+	"mot-bot/pkg/render"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// inlineCacheTime is how many seconds Telegram may cache the answer to an inline query
+	inlineCacheTime = 300
+	// maxInlinePlateLength is the length of the longest registration numbers
+	maxInlinePlateLength = 7
+)
+
+// handleInlineQuery answers "@botname AB12CDE" with a vehicle card that can
+// be shared into any chat
+func (b *Bot) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) error {
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       []interface{}{},
+		CacheTime:     inlineCacheTime,
+	}
+
+	// Telegram sends a query for every keystroke, and a dateless plate such
+	// as "AB1" is valid on the way to "AB12CDE", so only look up plates that
+	// can't grow any longer
+	registration, format, err := plate.Parse(query.Query)
+	if err != nil || !isCompleteInlinePlate(registration, format) {
+		return b.answerInlineQuery(answer)
+	}
+
+	result := lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+	if err := result.Err(); err != nil {
+		log.Printf("Error handling inline query for %s: %v", registration, err)
+		return b.answerInlineQuery(answer)
+	}
+
+	article := tgbotapi.NewInlineQueryResultArticleMarkdown(registration, inlineTitle(result), formatInlineSummary(result))
+	article.Description = inlineDescription(result)
+	answer.Results = append(answer.Results, article)
+
+	return b.answerInlineQuery(answer)
+}
+
+// handleChosenInlineResult logs a vehicle card that was shared inline. Only
+// shared cards are logged, as the queries themselves are sent while typing.
+func (b *Bot) handleChosenInlineResult(chosen *tgbotapi.ChosenInlineResult) {
+	if err := b.logger.LogRequest(chosen.From.ID, inlineUsername(chosen.From), chosen.ResultID, "Shared inline vehicle card"); err != nil {
+		log.Printf("Failed to log request: %v", err)
+	}
+}
+
+// isCompleteInlinePlate reports whether a registration is complete, rather
+// than possibly the start of a longer one. Dateless and Northern Ireland
+// plates can be the start of any other format until they reach full length.
+func isCompleteInlinePlate(registration string, format plate.Format) bool {
+	switch format {
+	case plate.FormatCurrent, plate.FormatPrefix, plate.FormatSuffix:
+		return true
+	default:
+		return len(registration) == maxInlinePlateLength
+	}
+}
+
+func (b *Bot) answerInlineQuery(answer tgbotapi.InlineConfig) error {
+	if _, err := b.bot.Request(answer); err != nil {
+		return fmt.Errorf("failed to answer inline query: %w", err)
+	}
+	return nil
+}
+
+// inlineUsername returns the best available identifier of the user for the request log
+func inlineUsername(user *tgbotapi.User) string {
+	switch {
+	case user.UserName != "":
+		return user.UserName
+	case user.FirstName != "" && user.LastName != "":
+		return fmt.Sprintf("%s %s", user.FirstName, user.LastName)
+	case user.FirstName != "":
+		return user.FirstName
+	default:
+		return "unknown"
+	}
+}
+
+// inlineTitle returns the title of the inline result, such as "AB12CDE FORD FOCUS"
+func inlineTitle(result *lookup.Result) string {
+	switch {
+	case result.MOT != nil:
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", result.Registration, result.MOT.Make, result.MOT.Model))
+	case result.VES != nil:
+		return strings.TrimSpace(fmt.Sprintf("%s %s %s", result.Registration, result.VES.Colour, result.VES.Make))
+	default:
+		return result.Registration
+	}
+}
+
+// inlineDescription returns a plain text one-line status shown in the inline results list
+func inlineDescription(result *lookup.Result) string {
+	var parts []string
+
+	if result.MOT != nil {
+		if result.MOT.OutstandingRecall() {
+			parts = append(parts, "RECALL OUTSTANDING")
+		}
+		if test := result.MOT.LatestTest(); test != nil {
+			parts = append(parts, fmt.Sprintf("MOT %s", test.TestResult))
+		}
+		if expiry, ok := result.MOT.LatestExpiryDate(); ok {
+			parts = append(parts, fmt.Sprintf("expires %s", expiry.Format("02.01.2006")))
+		} else if !result.MOT.MotTestDueDate.IsZero() {
+			parts = append(parts, fmt.Sprintf("first MOT due %s", result.MOT.MotTestDueDate))
+		}
+	} else if result.NoMOTRecord() {
+		parts = append(parts, "No MOT record yet")
+	}
+
+	if result.VES != nil {
+		parts = append(parts, fmt.Sprintf("Tax %s", result.VES.TaxStatus))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// formatInlineSummary renders a short vehicle card for sharing through inline mode
+func formatInlineSummary(result *lookup.Result) string {
+	var sb strings.Builder
+
+	markdown(&sb).Alerts(result)
+	if result.MOT != nil {
+		sb.WriteString(fmt.Sprintf("🚗 *%s* `%s %s`\n", result.Registration, result.MOT.Make, result.MOT.Model))
+		sb.WriteString(fmt.Sprintf("⛽ `%s`  🎨 `%s`  📅 `%s`\n", result.MOT.FuelType, result.MOT.PrimaryColour, result.MOT.FirstUsedDate))
+	} else {
+		sb.WriteString(fmt.Sprintf("🚗 *%s*\n", result.Registration))
+	}
+
+	switch {
+	case result.NoMOTRecord():
+		sb.WriteString("🔧 *MOT:* `no MOT record yet`\n")
+	case result.MOT == nil:
+		sb.WriteString("🔧 *MOT:* _unavailable_\n")
+	case result.MOT.LatestTest() == nil && !result.MOT.MotTestDueDate.IsZero():
+		sb.WriteString(fmt.Sprintf("🔧 *MOT:* `no tests yet`, first due `%s`\n", result.MOT.MotTestDueDate))
+	case result.MOT.LatestTest() == nil:
+		sb.WriteString("🔧 *MOT:* `no tests recorded yet`\n")
+	default:
+		test := result.MOT.LatestTest()
+		line := fmt.Sprintf("🔧 *MOT:* `%s`", test.TestResult)
+		if expiry, ok := result.MOT.LatestExpiryDate(); ok {
+			line += fmt.Sprintf(", expires `%s`", expiry.Format("02.01.2006"))
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	if result.VES != nil {
+		line := fmt.Sprintf("💰 *Tax:* `%s`", result.VES.TaxStatus)
+		if !result.VES.TaxDueDate.IsZero() {
+			line += fmt.Sprintf(", due `%s`", result.VES.TaxDueDate)
+		}
+		sb.WriteString(line + "\n")
+	} else {
+		sb.WriteString("💰 *Tax:* _unavailable_\n")
+	}
+
+	if result.MOT != nil && len(result.MOT.MotTests) > 0 {
+		mileage := mot.AnalyseMileage(result.MOT.MotTests)
+		line := fmt.Sprintf("🔎 *Mileage:* %s `%s`", render.MileageEmoji(&mileage), mileage.Verdict())
+		if n := len(mileage.Readings); n > 0 {
+			last := mileage.Readings[n-1]
+			line += fmt.Sprintf(", last `%s %s`", last.Value, last.Unit)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	markdown(&sb).FetchedAt(result)
+
+	return sb.String()
+}
@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleInlineQuery_OnlyCompletePlates(t *testing.T) {
+	motClient := &fakeMOTClient{}
+	b, fake := newTestBot(t, motClient, &fakeVESClient{}, Config{})
+	user := &tgbotapi.User{ID: 7, UserName: "alex"}
+
+	for _, query := range []string{"AB1", "AB12", "AB12C", "AB12CDE", "ABC12", "ABC1234", "AIZ1234"} {
+		require.NoError(t, b.handleInlineQuery(context.Background(), &tgbotapi.InlineQuery{ID: query, From: user, Query: query}))
+	}
+
+	assert.Equal(t, []string{"AB12CDE", "ABC1234", "AIZ1234"}, motClient.registrations())
+	assert.Len(t, fake.calls("answerInlineQuery"), 7)
+
+	logs, err := b.logger.GetRequestLogs(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, logs, "queries are not logged while typing")
+
+	b.handleChosenInlineResult(&tgbotapi.ChosenInlineResult{ResultID: "AB12CDE", From: user, Query: "AB12CDE"})
+
+	logs, err = b.logger.GetRequestLogs(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "AB12CDE", logs[0].CarPlate)
+	assert.Equal(t, int64(7), logs[0].UserID)
+}
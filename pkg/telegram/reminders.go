@@ -0,0 +1,286 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"mot-bot/pkg/db"
+	"mot-bot/pkg/lookup"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	remindCallbackPrefix         = "remind:"
+	cancelReminderCallbackPrefix = "unremind:"
+
+	reminderCheckInterval = time.Hour
+)
+
+// remindButton returns the "Remind me" button shown under a lookup reply
+func remindButton(registration string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🔔 Remind me", remindCallbackPrefix+registration)
+}
+
+// handleRemind subscribes the user to MOT and tax reminders for a vehicle
+func (b *Bot) handleRemind(ctx context.Context, query *tgbotapi.CallbackQuery, registration string) error {
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "Reminders can only be set from a chat with the bot.")
+	}
+	chatID := query.Message.Chat.ID
+
+	result := lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+	if err := result.Err(); err != nil {
+		if answerErr := b.answerCallback(query.ID, lookupErrorMessage(err)); answerErr != nil {
+			log.Printf("Error answering callback query: %v", answerErr)
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔔 *Reminders for* `%s`\n\n", registration))
+
+	var motExpiry, taxDue time.Time
+	if result.MOT != nil {
+		motExpiry, _ = result.MOT.MOTDueDate()
+	}
+	if result.VES != nil {
+		taxDue = result.VES.TaxDueDate.Time
+	}
+	sb.WriteString(b.addReminder(query.From.ID, chatID, registration, db.SubscriptionMOT, motExpiry, now))
+	sb.WriteString(b.addReminder(query.From.ID, chatID, registration, db.SubscriptionTax, taxDue, now))
+
+	if err := b.answerCallback(query.ID, ""); err != nil {
+		log.Printf("Error answering callback query: %v", err)
+	}
+
+	return b.sendMessage(chatID, sb.String())
+}
+
+// addReminder stores a single reminder and returns a line describing the
+// outcome. A zero due date means the date is not known.
+func (b *Bot) addReminder(userID, chatID int64, registration, kind string, dueDate, now time.Time) string {
+	label := reminderLabel(kind)
+	if dueDate.IsZero() {
+		return fmt.Sprintf("➖ %s: no due date available\n", label)
+	}
+	if dueDate.Before(now) {
+		return fmt.Sprintf("⚠️ %s: already expired on `%s`\n", label, dueDate.Format("02.01.2006"))
+	}
+
+	notifyAt := dueDate.AddDate(0, 0, -b.reminderDays)
+	soon := notifyAt.Before(now)
+	if soon {
+		notifyAt = now
+	}
+
+	if err := b.logger.AddSubscription(userID, chatID, registration, kind, dueDate, notifyAt); err != nil {
+		log.Printf("Failed to add subscription: %v", err)
+		return fmt.Sprintf("❌ %s: couldn't save the reminder\n", label)
+	}
+
+	if soon {
+		// The reminder is sent by the next check of due reminders
+		return fmt.Sprintf("✅ %s: due `%s`, less than %d days away, so I'll remind you within the hour\n",
+			label, dueDate.Format("02.01.2006"), b.reminderDays)
+	}
+	return fmt.Sprintf("✅ %s: due `%s`, I'll remind you on `%s`\n", label, dueDate.Format("02.01.2006"), notifyAt.Format("02.01.2006"))
+}
+
+// handleReminders lists the user's pending reminders with buttons to cancel them
+func (b *Bot) handleReminders(message *tgbotapi.Message) error {
+	text, markup, err := b.remindersList(message.From.ID)
+	if err != nil {
+		return err
+	}
+	return b.sendMessageWithMarkup(message.Chat.ID, text, markup)
+}
+
+// handleCancelReminder removes a reminder and refreshes the list it was
+// cancelled from. The callback data holds the ID of the user the list belongs
+// to, so that nobody else can change it in a group chat.
+func (b *Bot) handleCancelReminder(query *tgbotapi.CallbackQuery, data string) error {
+	ownerStr, idStr, ok := strings.Cut(data, ":")
+	if !ok {
+		return fmt.Errorf("invalid reminder callback %q", data)
+	}
+	ownerID, err := strconv.ParseInt(ownerStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid reminder owner %q: %w", ownerStr, err)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid reminder id %q: %w", idStr, err)
+	}
+
+	if query.From.ID != ownerID {
+		return b.answerCallback(query.ID, "Only the owner of these reminders can cancel them.")
+	}
+
+	deleted, err := b.logger.DeleteSubscription(query.From.ID, id)
+	if err != nil {
+		return err
+	}
+
+	answer := "Reminder cancelled"
+	if !deleted {
+		answer = "Reminder not found"
+	}
+	if err := b.answerCallback(query.ID, answer); err != nil {
+		log.Printf("Error answering callback query: %v", err)
+	}
+
+	if query.Message == nil {
+		return nil
+	}
+
+	text, markup, err := b.remindersList(ownerID)
+	if err != nil {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ParseMode = "Markdown"
+	if keyboard, ok := markup.(tgbotapi.InlineKeyboardMarkup); ok {
+		edit.ReplyMarkup = &keyboard
+	}
+	if _, err := b.bot.Send(edit); err != nil {
+		return fmt.Errorf("failed to update reminders list: %w", err)
+	}
+	return nil
+}
+
+// remindersList renders the pending reminders of a user and the keyboard to cancel them
+func (b *Bot) remindersList(userID int64) (string, interface{}, error) {
+	subs, err := b.logger.GetUserSubscriptions(userID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+
+	if len(subs) == 0 {
+		return "You have no active reminders. Look up a vehicle and tap \"🔔 Remind me\" to add one.", nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔔 *Your Reminders*\n\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, sub := range subs {
+		label := reminderLabel(sub.Kind)
+		sb.WriteString(fmt.Sprintf("📝 `%s` %s due `%s`, reminder on `%s`\n",
+			sub.CarPlate, label, sub.DueDate.Format("02.01.2006"), sub.NotifyAt.Format("02.01.2006")))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("❌ Cancel %s %s", sub.CarPlate, label),
+				fmt.Sprintf("%s%d:%d", cancelReminderCallbackPrefix, userID, sub.ID),
+			),
+		))
+	}
+
+	return sb.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// runReminders periodically sends the reminders that have become due, and
+// removes expired cached responses, until ctx is cancelled
+func (b *Bot) runReminders(ctx context.Context) {
+	ticker := time.NewTicker(reminderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		b.sendDueReminders(now)
+		b.purgeCache(now)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDueReminders sends every reminder that is due at now
+func (b *Bot) sendDueReminders(now time.Time) {
+	subs, err := b.logger.GetDueSubscriptions(now)
+	if err != nil {
+		log.Printf("Failed to get due reminders: %v", err)
+		return
+	}
+
+	for _, sub := range subs {
+		claimed, err := b.logger.ClaimSubscription(sub.ID)
+		if err != nil {
+			log.Printf("Failed to claim reminder %d: %v", sub.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		text := fmt.Sprintf("🔔 *Reminder*\n\nThe %s for `%s` %s.",
+			reminderLabel(sub.Kind), sub.CarPlate, dueDescription(sub.DueDate, now))
+		if err := b.sendMessage(sub.ChatID, text); err != nil {
+			log.Printf("Failed to send reminder %d: %v", sub.ID, err)
+			// Release the claim so the reminder is retried by the next check
+			if err := b.logger.ReleaseSubscription(sub.ID); err != nil {
+				log.Printf("Failed to release reminder %d: %v", sub.ID, err)
+			}
+		}
+	}
+}
+
+// purgeCache removes the cached responses that are too old to be used again
+func (b *Bot) purgeCache(now time.Time) {
+	if b.cacheTTL <= 0 {
+		return
+	}
+
+	deleted, err := b.logger.DeleteCachedResponsesBefore(now.Add(-b.cacheTTL))
+	if err != nil {
+		log.Printf("Failed to purge cached responses: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Purged %d expired cached responses", deleted)
+	}
+}
+
+// dueDescription describes when a due date falls, counted in calendar days from now
+func dueDescription(dueDate, now time.Time) string {
+	year, month, day := dueDate.UTC().Date()
+	due := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	year, month, day = now.UTC().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	days := int(due.Sub(today).Hours() / 24)
+	date := dueDate.Format("02.01.2006")
+
+	switch {
+	case days == 0:
+		return fmt.Sprintf("is due today (`%s`)", date)
+	case days == 1:
+		return fmt.Sprintf("is due tomorrow (`%s`)", date)
+	case days > 1:
+		return fmt.Sprintf("is due on `%s` (in %d days)", date, days)
+	case days == -1:
+		return fmt.Sprintf("was due yesterday (`%s`)", date)
+	default:
+		return fmt.Sprintf("was due on `%s` (%d days overdue)", date, -days)
+	}
+}
+
+// reminderLabel returns a human-readable name for a subscription kind
+func reminderLabel(kind string) string {
+	switch kind {
+	case db.SubscriptionMOT:
+		return "MOT"
+	case db.SubscriptionTax:
+		return "tax"
+	default:
+		return kind
+	}
+}
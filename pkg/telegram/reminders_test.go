@@ -0,0 +1,125 @@
+package telegram
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"mot-bot/pkg/db"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddReminder(t *testing.T) {
+	b, _ := newTestBot(t, nil, nil, Config{ReminderDays: 14})
+	now := time.Date(2030, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	text := b.addReminder(1, 10, "AB12CDE", db.SubscriptionMOT, time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), now)
+	assert.Equal(t, "✅ MOT: due `01.06.2030`, I'll remind you on `18.05.2030`\n", text)
+
+	text = b.addReminder(1, 10, "AB12CDE", db.SubscriptionTax, time.Date(2030, 4, 5, 0, 0, 0, 0, time.UTC), now)
+	assert.Equal(t, "✅ tax: due `05.04.2030`, less than 14 days away, so I'll remind you within the hour\n", text)
+
+	text = b.addReminder(1, 10, "AB12CDE", db.SubscriptionTax, time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC), now)
+	assert.Contains(t, text, "already expired")
+
+	subs, err := b.logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	// The reminder due within the reminder period is sent at the next check
+	assert.True(t, subs[0].NotifyAt.Equal(now))
+}
+
+func TestSendDueReminders(t *testing.T) {
+	b, fake := newTestBot(t, nil, nil, Config{ReminderDays: 14})
+	now := time.Date(2030, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, b.logger.AddSubscription(1, 10, "AB12CDE", db.SubscriptionMOT, now.AddDate(0, 0, 10), now.Add(-time.Hour)))
+	require.NoError(t, b.logger.AddSubscription(1, 10, "XY99ZZZ", db.SubscriptionTax, now.AddDate(0, 1, 0), now.AddDate(0, 0, 16)))
+
+	b.sendDueReminders(now)
+	// A second check must not send the claimed reminder again
+	b.sendDueReminders(now.Add(time.Hour))
+
+	sent := fake.calls("sendMessage")
+	require.Len(t, sent, 1)
+	assert.Equal(t, "10", sent[0].params["chat_id"])
+	assert.Contains(t, sent[0].params["text"], "The MOT for `AB12CDE` is due on `11.04.2030` (in 10 days).")
+
+	// The other reminder goes out once it is due
+	b.sendDueReminders(now.AddDate(0, 0, 16))
+	sent = fake.calls("sendMessage")
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[1].params["text"], "`XY99ZZZ`")
+}
+
+func TestSendDueReminders_RetriesFailedSend(t *testing.T) {
+	b, fake := newTestBot(t, nil, nil, Config{ReminderDays: 14})
+	now := time.Date(2030, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, b.logger.AddSubscription(1, 10, "AB12CDE", db.SubscriptionMOT, now.AddDate(0, 0, 10), now.Add(-time.Hour)))
+
+	fake.fail("sendMessage", true)
+	b.sendDueReminders(now)
+
+	// The reminder that couldn't be sent stays pending
+	subs, err := b.logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+
+	fake.fail("sendMessage", false)
+	b.sendDueReminders(now.Add(time.Hour))
+	b.sendDueReminders(now.Add(2 * time.Hour))
+
+	// The failed attempt is recorded too, and the reminder is sent only once
+	sent := fake.calls("sendMessage")
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[1].params["text"], "`AB12CDE`")
+
+	subs, err = b.logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+}
+
+func TestDueDescription(t *testing.T) {
+	now := time.Date(2030, 4, 1, 23, 0, 0, 0, time.UTC)
+	due := func(day int) time.Time { return time.Date(2030, 4, day, 0, 0, 0, 0, time.UTC) }
+
+	assert.Equal(t, "is due on `11.04.2030` (in 10 days)", dueDescription(due(11), now))
+	assert.Equal(t, "is due tomorrow (`02.04.2030`)", dueDescription(due(2), now))
+	assert.Equal(t, "is due today (`01.04.2030`)", dueDescription(due(1), now))
+	assert.Equal(t, "was due on `29.03.2030` (3 days overdue)", dueDescription(time.Date(2030, 3, 29, 0, 0, 0, 0, time.UTC), now))
+}
+
+func TestHandleCancelReminder_OnlyOwner(t *testing.T) {
+	b, fake := newTestBot(t, nil, nil, Config{ReminderDays: 14})
+	due := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, b.logger.AddSubscription(1, 10, "AB12CDE", db.SubscriptionMOT, due, due.AddDate(0, 0, -14)))
+
+	subs, err := b.logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	data := fmt.Sprintf("1:%d", subs[0].ID)
+
+	query := &tgbotapi.CallbackQuery{
+		ID:      "q1",
+		From:    &tgbotapi.User{ID: 2},
+		Message: &tgbotapi.Message{MessageID: 5, Chat: &tgbotapi.Chat{ID: 10}},
+	}
+	require.NoError(t, b.handleCancelReminder(query, data))
+	assert.Empty(t, fake.calls("editMessageText"))
+
+	subs, err = b.logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	assert.Len(t, subs, 1)
+
+	query.From.ID = 1
+	require.NoError(t, b.handleCancelReminder(query, data))
+	require.Len(t, fake.calls("editMessageText"), 1)
+
+	subs, err = b.logger.GetUserSubscriptions(1)
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+}
@@ -0,0 +1,25 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mot-bot/pkg/report"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleReport sends a PDF report of a vehicle: /report <reg>
+func (b *Bot) handleReport(ctx context.Context, message *tgbotapi.Message) error {
+	result, ok := b.lookupArgs(ctx, message.Chat.ID, strings.Fields(message.CommandArguments()),
+		"Usage: `/report <reg>`, for example `/report AB12CDE`")
+	if !ok {
+		return nil
+	}
+	registration := result.Registration
+
+	return b.sendFile(message.Chat.ID, fmt.Sprintf("report-%s.pdf", registration), report.PDF(result, time.Now()),
+		fmt.Sprintf("📄 *Vehicle report for* `%s`", registration))
+}
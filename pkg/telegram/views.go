@@ -0,0 +1,297 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mot-bot/pkg/lookup"
+	"mot-bot/pkg/mot"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Views of a lookup result that the inline buttons switch between
+const (
+	viewCallbackPrefix = "v:"
+
+	viewSummary  = "s"
+	viewHistory  = "h"
+	viewDefects  = "d"
+	viewTax      = "t"
+	viewMileage  = "m"
+	viewSystems  = "y"
+	viewCleanAir = "c"
+
+	// noopCallbackData is used by buttons that only show information
+	noopCallbackData = "noop"
+
+	historyPageSize = 3
+	defectsPageSize = 4
+)
+
+// viewCallbackData encodes a view and page of a vehicle as callback data
+func viewCallbackData(view, registration string, page int) string {
+	return fmt.Sprintf("%s%s:%s:%d", viewCallbackPrefix, view, registration, page)
+}
+
+// parseViewCallback decodes callback data created by viewCallbackData
+func parseViewCallback(data string) (view, registration string, page int, err error) {
+	parts := strings.Split(strings.TrimPrefix(data, viewCallbackPrefix), ":")
+	if len(parts) != 3 {
+		return "", "", 0, fmt.Errorf("invalid view callback data %q", data)
+	}
+	page, err = strconv.Atoi(parts[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid view callback page %q: %w", data, err)
+	}
+	return parts[0], parts[1], page, nil
+}
+
+// handleViewCallback switches the lookup message to another view or page
+func (b *Bot) handleViewCallback(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	view, registration, page, err := parseViewCallback(query.Data)
+	if err != nil {
+		if answerErr := b.answerCallback(query.ID, ""); answerErr != nil {
+			log.Printf("Error answering callback query: %v", answerErr)
+		}
+		return err
+	}
+
+	// Views only belong to lookup replies, inline cards have no buttons
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	result := lookup.Fetch(ctx, b.motClient, b.vesClient, registration)
+	if err := result.Err(); err != nil {
+		if answerErr := b.answerCallback(query.ID, lookupErrorMessage(err)); answerErr != nil {
+			log.Printf("Error answering callback query: %v", answerErr)
+		}
+		return err
+	}
+
+	if err := b.answerCallback(query.ID, ""); err != nil {
+		log.Printf("Error answering callback query: %v", err)
+	}
+
+	text, markup := renderView(result, view, page)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, markup)
+	edit.ParseMode = "Markdown"
+
+	if _, err := b.bot.Send(edit); err != nil {
+		// Pressing the button of the view already shown changes nothing
+		if strings.Contains(err.Error(), "message is not modified") {
+			return nil
+		}
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	return nil
+}
+
+// renderView returns the text and buttons of a view of a lookup result
+func renderView(result *lookup.Result, view string, page int) (string, tgbotapi.InlineKeyboardMarkup) {
+	var text string
+	pages := 1
+
+	switch view {
+	case viewHistory:
+		text, pages = formatHistoryPage(result, page)
+	case viewDefects:
+		text, pages = formatDefectsPage(result, page)
+	case viewTax:
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("📝 `%s`\n", result.Registration))
+		markdown(&sb).Tax(result)
+		text = sb.String()
+	case viewMileage:
+		text = formatMileageView(result)
+	case viewSystems:
+		text = formatSystemsView(result)
+	case viewCleanAir:
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("📝 `%s`\n", result.Registration))
+		markdown(&sb).CleanAir(result)
+		text = sb.String()
+	default:
+		return truncateMessage(formatSummary(result)), summaryKeyboard(result.Registration)
+	}
+
+	return truncateMessage(text), pageKeyboard(view, result.Registration, page, pages)
+}
+
+// truncateMessage cuts a message that can't be split, such as an edit, at
+// the last full line that fits into a Telegram message
+func truncateMessage(text string) string {
+	const ellipsis = "\n…"
+	if len(text) <= maxMessageLength {
+		return text
+	}
+	cut := strings.LastIndex(text[:maxMessageLength-len(ellipsis)], "\n")
+	if cut < 0 {
+		cut = 0
+	}
+	return text[:cut] + ellipsis
+}
+
+// summaryKeyboard returns the buttons under the summary card
+func summaryKeyboard(registration string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔧 MOT history", viewCallbackData(viewHistory, registration, 0)),
+			tgbotapi.NewInlineKeyboardButtonData("⚠️ Defects only", viewCallbackData(viewDefects, registration, 0)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💰 Tax", viewCallbackData(viewTax, registration, 0)),
+			tgbotapi.NewInlineKeyboardButtonData("📏 Mileage", viewCallbackData(viewMileage, registration, 0)),
+			tgbotapi.NewInlineKeyboardButtonData("🛠 Systems", viewCallbackData(viewSystems, registration, 0)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🌿 Clean air", viewCallbackData(viewCleanAir, registration, 0)),
+			chartButton(registration),
+			remindButton(registration),
+		),
+	)
+}
+
+// pageKeyboard returns the paging buttons of a view and a button back to the summary
+func pageKeyboard(view, registration string, page, pages int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if pages > 1 {
+		var nav []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("◀️", viewCallbackData(view, registration, page-1)))
+		}
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, pages), noopCallbackData))
+		if page < pages-1 {
+			nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("▶️", viewCallbackData(view, registration, page+1)))
+		}
+		rows = append(rows, nav)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Summary", viewCallbackData(viewSummary, registration, 0)),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// formatHistoryPage renders one page of MOT tests, newest first
+func formatHistoryPage(result *lookup.Result, page int) (string, int) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📝 `%s`\n", result.Registration))
+
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		markdown(&sb).History(result)
+		return sb.String(), 1
+	}
+
+	tests := newestFirst(result.MOT.MotTests)
+	pageTests, page, pages := paginate(tests, page, historyPageSize)
+
+	sb.WriteString(fmt.Sprintf("\n🔧 *MOT History* (%d tests, page %d/%d)\n\n", len(tests), page+1, pages))
+	w := markdown(&sb)
+	for _, test := range pageTests {
+		w.Test(result.Registration, test)
+		sb.WriteString("\n")
+	}
+	return sb.String(), pages
+}
+
+// formatDefectsPage renders one page of the MOT tests that had defects, newest first
+func formatDefectsPage(result *lookup.Result, page int) (string, int) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📝 `%s`\n", result.Registration))
+
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		markdown(&sb).History(result)
+		return sb.String(), 1
+	}
+
+	var tests []mot.MotTest
+	for _, test := range newestFirst(result.MOT.MotTests) {
+		if len(test.Defects) > 0 {
+			tests = append(tests, test)
+		}
+	}
+	if len(tests) == 0 {
+		sb.WriteString("\n✅ _No defects recorded in any MOT test_\n")
+		return sb.String(), 1
+	}
+
+	pageTests, page, pages := paginate(tests, page, defectsPageSize)
+
+	sb.WriteString(fmt.Sprintf("\n⚠️ *Defects* (%d tests, page %d/%d)\n\n", len(tests), page+1, pages))
+	w := markdown(&sb)
+	for _, test := range pageTests {
+		w.Test(result.Registration, test)
+		sb.WriteString("\n")
+	}
+	return sb.String(), pages
+}
+
+// formatMileageView renders the mileage check with every odometer reading
+func formatMileageView(result *lookup.Result) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📝 `%s`\n", result.Registration))
+
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		markdown(&sb).History(result)
+		return sb.String()
+	}
+
+	markdown(&sb).Mileage(result)
+
+	mileage := mot.AnalyseMileage(result.MOT.MotTests)
+	if len(mileage.Readings) > 0 {
+		sb.WriteString("\n📏 *Readings*\n\n")
+		for _, reading := range mileage.Readings {
+			marker := ""
+			if reading.Failed {
+				marker = " ❌"
+			}
+			sb.WriteString(fmt.Sprintf("`%s` `%s %s`%s\n", reading.Date.Format("02.01.2006"), reading.Value, reading.Unit, marker))
+		}
+	}
+	return sb.String()
+}
+
+// formatSystemsView renders the defect counts and history of each vehicle system
+func formatSystemsView(result *lookup.Result) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📝 `%s`\n", result.Registration))
+
+	if result.MOT == nil || len(result.MOT.MotTests) == 0 {
+		markdown(&sb).History(result)
+		return sb.String()
+	}
+
+	markdown(&sb).Systems(result)
+	return sb.String()
+}
+
+// newestFirst returns a copy of the tests sorted by completion date, newest first
+func newestFirst(tests []mot.MotTest) []mot.MotTest {
+	sorted := make([]mot.MotTest, len(tests))
+	copy(sorted, tests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CompletedDate.After(sorted[j].CompletedDate.Time)
+	})
+	return sorted
+}
+
+// paginate returns the items of a page, clamping the page into range, and the number of pages
+func paginate[T any](items []T, page, size int) ([]T, int, int) {
+	pages := (len(items) + size - 1) / size
+	if pages == 0 {
+		pages = 1
+	}
+	page = max(0, min(page, pages-1))
+
+	start := page * size
+	end := min(start+size, len(items))
+	return items[start:end], page, pages
+}
@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// webhookQueueSize is how many received updates may wait for the dispatcher
+	webhookQueueSize = 100
+
+	webhookShutdownTimeout = 10 * time.Second
+
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+)
+
+// WebhookConfig configures receiving updates through a webhook
+type WebhookConfig struct {
+	// URL is the public HTTPS address Telegram sends updates to
+	URL string
+	// Listen is the address the local HTTP server listens on
+	Listen string
+	// Secret is sent by Telegram in every request and checked by the bot, it is required
+	Secret string
+}
+
+// StartWebhook registers the webhook with Telegram and receives updates
+// through an HTTP server until ctx is cancelled
+func (b *Bot) StartWebhook(ctx context.Context, config WebhookConfig) error {
+	webhookURL, err := url.Parse(config.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if config.Secret == "" {
+		return errors.New("a webhook secret is required to authenticate webhook requests")
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("url", config.URL)
+	params.AddNonEmpty("secret_token", config.Secret)
+	if _, err := b.bot.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	listener, err := net.Listen("tcp", config.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen for webhook updates: %w", err)
+	}
+	log.Printf("Listening for webhook updates on %s%s", config.Listen, path)
+
+	return b.serveWebhook(ctx, listener, path, config.Secret)
+}
+
+// serveWebhook receives updates on listener until ctx is cancelled. On
+// shutdown the server is stopped before the received updates are drained,
+// so every update acknowledged to Telegram is handled.
+func (b *Bot) serveWebhook(ctx context.Context, listener net.Listener, path, secret string) error {
+	updates := make(chan tgbotapi.Update, webhookQueueSize)
+	mux := http.NewServeMux()
+	mux.Handle(path, b.webhookHandler(secret, updates))
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+			cancel()
+		}
+	}()
+
+	err := b.run(ctx, updates, func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+		defer shutdownCancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to shut down webhook server: %v", err)
+		}
+	})
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("webhook server failed: %w", err)
+	default:
+		return err
+	}
+}
+
+// webhookHandler checks the secret token of incoming requests and queues
+// their updates for the dispatcher
+func (b *Bot) webhookHandler(secret string, updates chan<- tgbotapi.Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(secret)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		update, err := b.bot.HandleUpdate(r)
+		if err != nil {
+			log.Printf("Failed to decode webhook update: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- *update:
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+			// Telegram retries updates that were not acknowledged
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postUpdate sends a text message update to the webhook and returns the
+// status code, or an error if the request could not be made
+func postUpdate(client *http.Client, url, secret string, id int, chatID int64, text string) (int, error) {
+	body := fmt.Sprintf(`{"update_id":%d,"message":{"message_id":%d,"date":0,"chat":{"id":%d,"type":"private"},"text":%q}}`, id, id, chatID, text)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(secretTokenHeader, secret)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestServeWebhook_HandlesAcknowledgedUpdatesOnShutdown(t *testing.T) {
+	motClient := &fakeMOTClient{block: make(chan struct{}), started: make(chan string, 100)}
+	b, _ := newTestBot(t, motClient, &fakeVESClient{}, Config{Workers: 1, RequestTimeout: 10 * time.Second})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String() + "/webhook"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.serveWebhook(ctx, listener, "/webhook", "secret") }()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+
+	// The first lookup holds up the only worker while the bot shuts down
+	status, err := postUpdate(client, url, "secret", 1, 1, "AB12CDE")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "AB12CDE", <-motClient.started)
+	cancel()
+
+	// Keep sending until the server stops accepting updates
+	var acknowledged []string
+	for i := 10; i < 60; i++ {
+		registration := fmt.Sprintf("AB%02dCDE", i)
+		status, err := postUpdate(client, url, "secret", i, int64(i), registration)
+		if err != nil {
+			break
+		}
+		if status == http.StatusOK {
+			acknowledged = append(acknowledged, registration)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(motClient.block)
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(10 * time.Second):
+		t.Fatal("serveWebhook did not return")
+	}
+
+	for _, registration := range acknowledged {
+		assert.Contains(t, motClient.registrations(), registration)
+	}
+}
+
+func TestWebhookHandler_RequiresSecret(t *testing.T) {
+	b, _ := newTestBot(t, nil, nil, Config{})
+	updates := make(chan tgbotapi.Update, 1)
+	server := httptest.NewServer(b.webhookHandler("secret", updates))
+	t.Cleanup(server.Close)
+
+	status, err := postUpdate(server.Client(), server.URL, "", 1, 1, "AB12CDE")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, err = postUpdate(server.Client(), server.URL, "wrong", 2, 1, "AB12CDE")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, err = postUpdate(server.Client(), server.URL, "secret", 3, 1, "AB12CDE")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 3, (<-updates).UpdateID)
+}
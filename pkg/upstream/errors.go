@@ -0,0 +1,70 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kinds of upstream failures, use errors.Is to check for them
+var (
+	ErrNotFound     = errors.New("vehicle not found")
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorised = errors.New("unauthorised")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrUnavailable  = errors.New("service unavailable")
+)
+
+// Error is an unsuccessful response from the DVSA or DVLA APIs
+type Error struct {
+	Service    string
+	StatusCode int
+	Code       string
+	Message    string
+	Kind       error
+}
+
+// NewError creates an Error for a response status code and the details decoded from its body
+func NewError(service string, statusCode int, code, message string) *Error {
+	return &Error{
+		Service:    service,
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    message,
+		Kind:       KindForStatus(statusCode),
+	}
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s API returned status %d", e.Service, e.StatusCode)
+	if e.Code != "" {
+		msg += fmt.Sprintf(" (%s)", e.Code)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// KindForStatus returns the kind of failure an HTTP status code represents,
+// or nil if it doesn't match any of them
+func KindForStatus(statusCode int) error {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusBadRequest, statusCode == http.StatusUnprocessableEntity:
+		return ErrBadRequest
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return ErrUnauthorised
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode >= 500:
+		return ErrUnavailable
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,220 @@
+package upstream
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Policy configures retries and client-side rate limiting of upstream requests
+type Policy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled on every further retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. Retry-After waits are not capped, but a
+	// response is returned instead of retried if the wait would outlast the
+	// deadline of the request.
+	MaxDelay time.Duration
+	// Rate is the number of requests per second, 0 disables the limit
+	Rate float64
+	// Burst is the number of requests that may be sent at once
+	Burst int
+	// RetryNonIdempotent also retries requests such as POST after network
+	// errors and 5xx responses, for APIs where they only read data
+	RetryNonIdempotent bool
+}
+
+// Transport retries failed idempotent requests and 429/5xx responses with
+// jittered exponential backoff and limits the request rate with a token bucket
+type Transport struct {
+	base    http.RoundTripper
+	policy  Policy
+	limiter *limiter
+}
+
+func NewTransport(base http.RoundTripper, policy Policy) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t := &Transport{
+		base:   base,
+		policy: policy,
+	}
+	if policy.Rate > 0 {
+		t.limiter = newLimiter(policy.Rate, policy.Burst)
+	}
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= t.policy.MaxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				// Waiting less than the server asked for would only be rejected
+				// again, so give up if the wait doesn't fit before the deadline
+				if deadline, ok := ctx.Deadline(); ok && time.Now().Add(retryAfter).After(deadline) {
+					return resp, nil
+				}
+				delay = retryAfter
+			}
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the jittered delay before the given retry
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.policy.BaseDelay << attempt
+	if delay > t.policy.MaxDelay || delay <= 0 {
+		delay = t.policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Equal jitter: half fixed, half random
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// shouldRetry reports whether a request that got resp or err may be sent again
+func (t *Transport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return t.policy.RetryNonIdempotent || isIdempotent(req)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		// The request was rejected before being processed
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return t.policy.RetryNonIdempotent || isIdempotent(req)
+	default:
+		return false
+	}
+}
+
+// isIdempotent follows net/http: safe methods, PUT and DELETE are idempotent,
+// as is any request with an Idempotency-Key or X-Idempotency-Key header entry
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if _, ok := req.Header["Idempotency-Key"]; ok {
+		return true
+	}
+	if _, ok := req.Header["X-Idempotency-Key"]; ok {
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// limiter is a token bucket that refills at rate tokens per second up to burst
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait takes a token, blocking until one is available or ctx is done
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	tokens := l.tokens
+	l.mu.Unlock()
+
+	if tokens >= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(-tokens / l.rate * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Give back the reserved token
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
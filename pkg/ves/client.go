@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"mot-bot/pkg/upstream"
 	"net/http"
 	"time"
 )
@@ -19,11 +21,20 @@ type Client struct {
 	httpClient *http.Client
 }
 
-// Custom time type to handle the API's date format
+// CustomTime is a DVLA date. Most dates are "YYYY-MM-DD", but some, such as
+// monthOfFirstRegistration, only give the month as "YYYY-MM".
 type CustomTime struct {
 	time.Time
+
+	// MonthOnly is set if the date only gives the month
+	MonthOnly bool
 }
 
+const (
+	dateLayout  = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
 func (ct *CustomTime) UnmarshalJSON(b []byte) error {
 	// Remove quotes from the JSON string
 	s := string(b)
@@ -34,34 +45,117 @@ func (ct *CustomTime) UnmarshalJSON(b []byte) error {
 		s = s[:len(s)-1]
 	}
 
-	// Parse the date in YYYY-MM-DD format
-	t, err := time.Parse("2006-01-02", s)
+	// Leave missing dates as the zero time
+	if s == "" || s == "null" {
+		*ct = CustomTime{}
+		return nil
+	}
+
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		*ct = CustomTime{Time: t}
+		return nil
+	}
+
+	t, err := time.Parse(monthLayout, s)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid date %q: %w", s, err)
 	}
-	ct.Time = t
+	*ct = CustomTime{Time: t, MonthOnly: true}
 	return nil
 }
 
+func (ct CustomTime) MarshalJSON() ([]byte, error) {
+	if ct.IsZero() {
+		return []byte("null"), nil
+	}
+	if ct.MonthOnly {
+		return []byte(`"` + ct.Format(monthLayout) + `"`), nil
+	}
+	return []byte(`"` + ct.Format(dateLayout) + `"`), nil
+}
+
+// String returns the date as DD.MM.YYYY, or MM.YYYY if only the month is
+// known, or an empty string if the date is missing
+func (ct CustomTime) String() string {
+	switch {
+	case ct.IsZero():
+		return ""
+	case ct.MonthOnly:
+		return ct.Format("01.2006")
+	default:
+		return ct.Format("02.01.2006")
+	}
+}
+
+// Vehicle is a vehicle returned by the DVLA Vehicle Enquiry Service
 type Vehicle struct {
-	RegistrationNumber  string     `json:"registrationNumber"`
-	TaxStatus           string     `json:"taxStatus"`
-	TaxDueDate          CustomTime `json:"taxDueDate"`
-	Wheelplan           string     `json:"wheelplan"`
-	DateOfLastV5CIssued CustomTime `json:"dateOfLastV5CIssued"`
-	EuroStatus          string     `json:"euroStatus"`
+	RegistrationNumber           string     `json:"registrationNumber"`
+	TaxStatus                    string     `json:"taxStatus"`
+	TaxDueDate                   CustomTime `json:"taxDueDate"`
+	ArtEndDate                   CustomTime `json:"artEndDate"`
+	MotStatus                    string     `json:"motStatus"`
+	MotExpiryDate                CustomTime `json:"motExpiryDate"`
+	Make                         string     `json:"make"`
+	Colour                       string     `json:"colour"`
+	YearOfManufacture            int        `json:"yearOfManufacture"`
+	MonthOfFirstRegistration     CustomTime `json:"monthOfFirstRegistration"`
+	MonthOfFirstDvlaRegistration CustomTime `json:"monthOfFirstDvlaRegistration"`
+	EngineCapacity               int        `json:"engineCapacity"`
+	Co2Emissions                 int        `json:"co2Emissions"`
+	FuelType                     string     `json:"fuelType"`
+	MarkedForExport              bool       `json:"markedForExport"`
+	TypeApproval                 string     `json:"typeApproval"`
+	RevenueWeight                int        `json:"revenueWeight"`
+	RealDrivingEmissions         string     `json:"realDrivingEmissions"`
+	Wheelplan                    string     `json:"wheelplan"`
+	DateOfLastV5CIssued          CustomTime `json:"dateOfLastV5CIssued"`
+	EuroStatus                   string     `json:"euroStatus"`
+
+	// FetchedAt is when the data was retrieved from the API
+	FetchedAt time.Time `json:"-"`
+}
+
+// errorResponse is the error body returned by the DVLA API
+type errorResponse struct {
+	Errors []struct {
+		Status string `json:"status"`
+		Code   string `json:"code"`
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+// decodeError converts an unsuccessful response into an *upstream.Error
+func decodeError(resp *http.Response) error {
+	var body errorResponse
+	// The body is optional, so a missing or malformed one is not an error
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	var code, message string
+	if len(body.Errors) > 0 {
+		code = body.Errors[0].Code
+		message = body.Errors[0].Title
+		if body.Errors[0].Detail != "" {
+			message = body.Errors[0].Detail
+		}
+	}
+	return upstream.NewError("VES", resp.StatusCode, code, message)
 }
 
 type requestBody struct {
 	RegistrationNumber string `json:"registrationNumber"`
 }
 
-func NewClient(baseURL, apiKey string) *Client {
+func NewClient(baseURL, apiKey string, policy upstream.Policy) *Client {
+	// The enquiry is a POST but only reads data, so it is safe to retry
+	policy.RetryNonIdempotent = true
+
 	return &Client{
 		baseURL: baseURL,
 		apiKey:  apiKey,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: upstream.NewTransport(http.DefaultTransport, policy),
 		},
 	}
 }
@@ -88,18 +182,23 @@ func (c *Client) GetVehicleByRegistration(ctx context.Context, registration stri
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		// A timeout or cancellation of the caller is not a fault of the service
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to send request: %w: %w", upstream.ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, decodeError(resp)
 	}
 
 	var vehicle Vehicle
 	if err := json.NewDecoder(resp.Body).Decode(&vehicle); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	vehicle.FetchedAt = time.Now()
 
 	return &vehicle, nil
 }
@@ -0,0 +1,58 @@
+package ves
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVehicle_JSON(t *testing.T) {
+	var vehicle Vehicle
+	err := json.Unmarshal([]byte(`{
+		"registrationNumber": "AB12CDE",
+		"taxStatus": "Taxed",
+		"taxDueDate": "2025-03-01",
+		"artEndDate": null,
+		"motStatus": "Valid",
+		"motExpiryDate": "2025-06-30",
+		"make": "FORD",
+		"colour": "BLUE",
+		"yearOfManufacture": 2018,
+		"monthOfFirstRegistration": "2018-04",
+		"engineCapacity": 1596,
+		"co2Emissions": 129,
+		"fuelType": "PETROL",
+		"markedForExport": true,
+		"typeApproval": "M1",
+		"revenueWeight": 1890,
+		"wheelplan": "2 AXLE RIGID BODY",
+		"dateOfLastV5CIssued": "",
+		"euroStatus": "EURO 6"
+	}`), &vehicle)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), vehicle.TaxDueDate.Time)
+	assert.True(t, vehicle.ArtEndDate.IsZero())
+	assert.True(t, vehicle.DateOfLastV5CIssued.IsZero())
+	assert.True(t, vehicle.MonthOfFirstRegistration.MonthOnly)
+	assert.Equal(t, "04.2018", vehicle.MonthOfFirstRegistration.String())
+	assert.Equal(t, "30.06.2025", vehicle.MotExpiryDate.String())
+	assert.Equal(t, 129, vehicle.Co2Emissions)
+	assert.True(t, vehicle.MarkedForExport)
+
+	data, err := json.Marshal(vehicle)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"monthOfFirstRegistration":"2018-04"`)
+
+	var decoded Vehicle
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, vehicle, decoded)
+}
+
+func TestCustomTime_Invalid(t *testing.T) {
+	var ct CustomTime
+	assert.Error(t, json.Unmarshal([]byte(`"01/04/2018"`), &ct))
+}